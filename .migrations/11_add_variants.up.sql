@@ -0,0 +1,3 @@
+ALTER TABLE urls ADD COLUMN variants JSONB;
+ALTER TABLE urls ADD COLUMN sticky_variants BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE clicks ADD COLUMN variant TEXT NOT NULL DEFAULT '';
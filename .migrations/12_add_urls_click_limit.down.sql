@@ -0,0 +1,2 @@
+ALTER TABLE urls DROP COLUMN IF EXISTS remaining_clicks;
+ALTER TABLE urls DROP COLUMN IF EXISTS max_clicks;
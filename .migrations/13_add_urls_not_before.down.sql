@@ -0,0 +1,2 @@
+ALTER TABLE urls DROP COLUMN IF EXISTS placeholder_url;
+ALTER TABLE urls DROP COLUMN IF EXISTS not_before;
@@ -0,0 +1,2 @@
+ALTER TABLE urls ADD COLUMN not_before TIMESTAMP WITH TIME ZONE;
+ALTER TABLE urls ADD COLUMN placeholder_url TEXT NOT NULL DEFAULT '';
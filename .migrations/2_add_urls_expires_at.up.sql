@@ -0,0 +1 @@
+ALTER TABLE urls ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
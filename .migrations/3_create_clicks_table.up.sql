@@ -0,0 +1,10 @@
+CREATE TABLE clicks (
+    id BIGSERIAL PRIMARY KEY,
+    short_code TEXT NOT NULL,
+    clicked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    referrer TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    client_ip TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_clicks_short_code_clicked_at ON clicks (short_code, clicked_at);
@@ -0,0 +1 @@
+ALTER TABLE urls ADD COLUMN disabled_at TIMESTAMP WITH TIME ZONE;
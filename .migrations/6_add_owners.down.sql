@@ -0,0 +1,3 @@
+ALTER TABLE api_keys DROP COLUMN IF EXISTS owner;
+DROP INDEX IF EXISTS idx_urls_owner_created_at;
+ALTER TABLE urls DROP COLUMN IF EXISTS owner;
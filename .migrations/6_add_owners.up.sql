@@ -0,0 +1,6 @@
+ALTER TABLE urls ADD COLUMN owner TEXT NOT NULL DEFAULT '';
+CREATE INDEX idx_urls_owner_created_at ON urls (owner, created_at);
+
+-- Keys created before ownership existed own the links created with them under their own name.
+ALTER TABLE api_keys ADD COLUMN owner TEXT NOT NULL DEFAULT '';
+UPDATE api_keys SET owner = name;
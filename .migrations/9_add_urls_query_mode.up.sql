@@ -0,0 +1 @@
+ALTER TABLE urls ADD COLUMN query_mode TEXT NOT NULL DEFAULT '';
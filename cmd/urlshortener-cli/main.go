@@ -6,25 +6,79 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	proto "github.com/ndajr/urlshortener-go/proto/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var (
 	grpcServerEndpoint = flag.String("grpc-server-endpoint", "localhost:8081", "gRPC server endpoint")
+	customAlias        = flag.String("alias", "", "custom alias to use as the short code (shorten only)")
+	deduplicate        = flag.Bool("dedupe", false, "reuse an existing short code for the same url (shorten only)")
+	expiresIn          = flag.Duration("expires-in", 0, "how long the short link stays valid, e.g. 72h (shorten only)")
+	notBefore          = flag.String("not-before", "", "RFC 3339 time at which the short link goes live, e.g. 2026-11-03T09:00:00Z (shorten only)")
+	placeholderURL     = flag.String("placeholder", "", "url to redirect visits to before the link goes live, requires -not-before (shorten only)")
+	redirectStatus     = flag.Int("redirect-status", 0, "HTTP status of the redirect: 301, 302, 307 or 308, defaults to 302 (shorten only)")
+	queryPassthrough   = flag.String("query", "", "pass the query parameters of visits on to the url: keep, override or append (shorten only)")
+	maxClicks          = flag.Int("max-clicks", 0, "number of redirects after which the link is used up, e.g. 1 for a one-time link (shorten only)")
+	stickyVariants     = flag.Bool("sticky", false, "keep returning visitors on the same variant (shorten only)")
+	password           = flag.String("password", "", "password visitors must enter before being redirected (shorten), or to look up a protected link (get)")
+	keyOwner           = flag.String("owner", "", "team or client owning the links created with the key, defaults to its name (create-key only)")
+	apiKey             = flag.String("api-key", os.Getenv("URLSHORTENER_API_KEY"), "API key to authenticate with, defaults to $URLSHORTENER_API_KEY")
 )
 
-const usage = `Usage: urlshortener [flags] <command> <value>
+var (
+	// targets collects the -target flags of a shorten command, in order.
+	targets targetFlags
+	// variants collects the -variant flags of a shorten command, in order.
+	variants variantFlags
+)
+
+func init() {
+	flag.Var(&targets, "target", "targeting rule as conditions@url, e.g. os=ios,device=tablet@https://example.com/ipad; conditions are os, device, lang and country, and the flag can be repeated (shorten only)")
+	flag.Var(&variants, "variant", "weighted variant to split visits between as [name:]weight@url, e.g. video:30@https://example.com/video; repeat it for each variant (shorten only)")
+}
+
+// scopes maps the scope names accepted by create-key to their API values.
+var scopes = map[string]proto.APIKeyScope{
+	"create": proto.APIKeyScope_API_KEY_SCOPE_CREATE,
+	"read":   proto.APIKeyScope_API_KEY_SCOPE_READ,
+	"admin":  proto.APIKeyScope_API_KEY_SCOPE_ADMIN,
+}
+
+// queryModes maps the modes accepted by -query to their API values.
+var queryModes = map[string]proto.QueryPassthrough{
+	"":         proto.QueryPassthrough_QUERY_PASSTHROUGH_UNSPECIFIED,
+	"keep":     proto.QueryPassthrough_QUERY_PASSTHROUGH_KEEP,
+	"override": proto.QueryPassthrough_QUERY_PASSTHROUGH_OVERRIDE,
+	"append":   proto.QueryPassthrough_QUERY_PASSTHROUGH_APPEND,
+}
+
+const usage = `Usage: urlshortener [flags] <command> [values]
 
 A CLI to interact with the URL shortener service.
 
 Commands:
   shorten <url>    Shortens a long URL.
   get <code>       Retrieves the original URL from a short code.
+  list [text]      Lists the most recent short links, optionally those whose URL contains text.
+  stats <code>     Shows click statistics for a short code.
+  update <code> <url>
+                   Points an existing short code at a new URL.
+  disable <code>   Disables a short link so it stops redirecting.
+  delete <code>    Permanently deletes a short link.
+  create-key <name> <create|read|admin>
+                   Creates an API key and prints it. It cannot be shown again.
+  list-keys        Lists all API keys.
+  revoke-key <id>  Revokes an API key.
 
 Flags:
 `
@@ -37,14 +91,13 @@ func main() {
 	flag.Parse()
 
 	args := flag.Args()
-	if len(args) != 2 {
-		fmt.Fprintln(os.Stderr, "Error: invalid arguments. Expected a command and a value.")
+	if !validArgs(args) {
+		fmt.Fprintln(os.Stderr, "Error: invalid arguments. Expected a command and its values.")
 		flag.Usage()
 		os.Exit(1)
 	}
 
 	command := args[0]
-	value := args[1]
 
 	conn, err := grpc.NewClient(*grpcServerEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -60,25 +113,88 @@ func main() {
 
 	client := proto.NewURLShortenerServiceClient(conn)
 	ctx := context.Background()
+	if *apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+*apiKey)
+	}
 
 	switch command {
 	case "shorten":
-		shortenURLCmd(ctx, client, value)
+		shortenURLCmd(ctx, client, args[1])
 	case "get":
-		getURLCmd(ctx, client, value)
+		getURLCmd(ctx, client, args[1])
+	case "list":
+		listURLsCmd(ctx, client, strings.Join(args[1:], " "))
+	case "stats":
+		getLinkStatsCmd(ctx, client, args[1])
+	case "update":
+		updateURLCmd(ctx, client, args[1], args[2])
+	case "disable":
+		disableURLCmd(ctx, client, args[1])
+	case "delete":
+		deleteURLCmd(ctx, client, args[1])
+	case "create-key":
+		createAPIKeyCmd(ctx, client, args[1], args[2])
+	case "list-keys":
+		listAPIKeysCmd(ctx, client)
+	case "revoke-key":
+		revokeAPIKeyCmd(ctx, client, args[1])
+	}
+}
+
+// validArgs checks that args hold a known command followed by the number of values it expects.
+func validArgs(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "shorten", "get", "stats", "disable", "delete", "revoke-key":
+		return len(args) == 2
+	case "update", "create-key":
+		return len(args) == 3
+	case "list-keys":
+		return len(args) == 1
+	case "list":
+		return len(args) <= 2
 	default:
-		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n", command)
-		flag.Usage()
-		os.Exit(1)
+		return false
 	}
 }
 
 func shortenURLCmd(ctx context.Context, client proto.URLShortenerServiceClient, originalURL string) {
-	res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{
-		OriginalUrl: originalURL,
-	})
+	queryMode, ok := queryModes[*queryPassthrough]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown query mode %q, expected keep, override or append\n", *queryPassthrough)
+		os.Exit(1)
+	}
+	req := &proto.ShortenURLRequest{
+		OriginalUrl:      originalURL,
+		CustomAlias:      *customAlias,
+		Password:         *password,
+		RedirectStatus:   int32(*redirectStatus),
+		QueryPassthrough: queryMode,
+		Targeting:        targets,
+		Variants:         variants,
+		StickyVariants:   *stickyVariants,
+		MaxClicks:        int32(*maxClicks),
+		PlaceholderUrl:   *placeholderURL,
+	}
+	if *expiresIn > 0 {
+		req.ExpiresAt = timestamppb.New(time.Now().Add(*expiresIn))
+	}
+	if *notBefore != "" {
+		t, err := time.Parse(time.RFC3339, *notBefore)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -not-before %q, expected an RFC 3339 time\n", *notBefore)
+			os.Exit(1)
+		}
+		req.NotBefore = timestamppb.New(t)
+	}
+	if *deduplicate {
+		req.Deduplicate = deduplicate
+	}
+	res, err := client.ShortenURL(ctx, req)
 	if err != nil {
-		if s := status.Convert(err); s.Code() == codes.InvalidArgument {
+		if s := status.Convert(err); s.Code() == codes.InvalidArgument || s.Code() == codes.AlreadyExists {
 			fmt.Fprintf(os.Stderr, "Error: %s\n", s.Message())
 			os.Exit(1)
 		}
@@ -90,13 +206,203 @@ func shortenURLCmd(ctx context.Context, client proto.URLShortenerServiceClient,
 func getURLCmd(ctx context.Context, client proto.URLShortenerServiceClient, shortCode string) {
 	res, err := client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{
 		ShortCode: shortCode,
+		Password:  *password,
 	})
 	if err != nil {
-		if s := status.Convert(err); s.Code() == codes.NotFound {
+		switch s := status.Convert(err); s.Code() {
+		case codes.NotFound:
 			fmt.Println("url not found")
 			return
+		case codes.FailedPrecondition, codes.Unauthenticated, codes.ResourceExhausted:
+			fmt.Println(s.Message())
+			return
 		}
 		log.Fatalf("could not get url: %v", err)
 	}
 	fmt.Printf("original url: %s\n", res.OriginalUrl)
 }
+
+func listURLsCmd(ctx context.Context, client proto.URLShortenerServiceClient, contains string) {
+	res, err := client.ListURLs(ctx, &proto.ListURLsRequest{
+		DestinationContains: contains,
+	})
+	if err != nil {
+		log.Fatalf("could not list urls: %v", err)
+	}
+	for _, url := range res.Urls {
+		fmt.Printf("%s  %s  %s\n", url.CreatedAt.AsTime().Format(time.RFC3339), url.ShortCode, url.OriginalUrl)
+	}
+}
+
+func getLinkStatsCmd(ctx context.Context, client proto.URLShortenerServiceClient, shortCode string) {
+	res, err := client.GetLinkStats(ctx, &proto.GetLinkStatsRequest{
+		ShortCode: shortCode,
+	})
+	if err != nil {
+		if s := status.Convert(err); s.Code() == codes.NotFound {
+			fmt.Println("url not found")
+			return
+		}
+		log.Fatalf("could not get link stats: %v", err)
+	}
+	fmt.Printf("total clicks: %d\n", res.TotalClicks)
+	for _, d := range res.DailyClicks {
+		fmt.Printf("%s: %d\n", d.Date, d.Clicks)
+	}
+	for _, v := range res.VariantClicks {
+		fmt.Printf("variant %s: %d\n", v.Variant, v.Clicks)
+	}
+}
+
+func updateURLCmd(ctx context.Context, client proto.URLShortenerServiceClient, shortCode, originalURL string) {
+	res, err := client.UpdateURL(ctx, &proto.UpdateURLRequest{
+		ShortCode:   shortCode,
+		OriginalUrl: originalURL,
+	})
+	if err != nil {
+		switch s := status.Convert(err); s.Code() {
+		case codes.NotFound:
+			fmt.Println("url not found")
+			return
+		case codes.InvalidArgument:
+			fmt.Fprintf(os.Stderr, "Error: %s\n", s.Message())
+			os.Exit(1)
+		}
+		log.Fatalf("could not update url: %v", err)
+	}
+	fmt.Printf("updated url: %s -> %s\n", shortCode, res.OriginalUrl)
+}
+
+func disableURLCmd(ctx context.Context, client proto.URLShortenerServiceClient, shortCode string) {
+	_, err := client.DisableURL(ctx, &proto.DisableURLRequest{
+		ShortCode: shortCode,
+	})
+	if err != nil {
+		if s := status.Convert(err); s.Code() == codes.NotFound {
+			fmt.Println("url not found")
+			return
+		}
+		log.Fatalf("could not disable url: %v", err)
+	}
+	fmt.Printf("disabled url: %s\n", shortCode)
+}
+
+func deleteURLCmd(ctx context.Context, client proto.URLShortenerServiceClient, shortCode string) {
+	_, err := client.DeleteURL(ctx, &proto.DeleteURLRequest{
+		ShortCode: shortCode,
+	})
+	if err != nil {
+		if s := status.Convert(err); s.Code() == codes.NotFound {
+			fmt.Println("url not found")
+			return
+		}
+		log.Fatalf("could not delete url: %v", err)
+	}
+	fmt.Printf("deleted url: %s\n", shortCode)
+}
+
+func createAPIKeyCmd(ctx context.Context, client proto.URLShortenerServiceClient, name, scope string) {
+	protoScope, ok := scopes[scope]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown scope %q, expected create, read or admin\n", scope)
+		os.Exit(1)
+	}
+	res, err := client.CreateAPIKey(ctx, &proto.CreateAPIKeyRequest{
+		Name:  name,
+		Scope: protoScope,
+		Owner: *keyOwner,
+	})
+	if err != nil {
+		if s := status.Convert(err); s.Code() == codes.InvalidArgument || s.Code() == codes.AlreadyExists {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", s.Message())
+			os.Exit(1)
+		}
+		log.Fatalf("could not create api key: %v", err)
+	}
+	fmt.Printf("created api key %s (%s, owner %s): %s\n", res.ApiKey.Id, res.ApiKey.Name, res.ApiKey.Owner, res.Key)
+}
+
+func listAPIKeysCmd(ctx context.Context, client proto.URLShortenerServiceClient) {
+	res, err := client.ListAPIKeys(ctx, &proto.ListAPIKeysRequest{})
+	if err != nil {
+		log.Fatalf("could not list api keys: %v", err)
+	}
+	for _, key := range res.ApiKeys {
+		revoked := ""
+		if key.RevokedAt != nil {
+			revoked = "  revoked " + key.RevokedAt.AsTime().Format(time.RFC3339)
+		}
+		fmt.Printf("%s  %s  %s  %s  %s%s\n", key.CreatedAt.AsTime().Format(time.RFC3339), key.Id, key.Name, key.Owner, key.Scope, revoked)
+	}
+}
+
+func revokeAPIKeyCmd(ctx context.Context, client proto.URLShortenerServiceClient, id string) {
+	_, err := client.RevokeAPIKey(ctx, &proto.RevokeAPIKeyRequest{
+		Id: id,
+	})
+	if err != nil {
+		if s := status.Convert(err); s.Code() == codes.NotFound {
+			fmt.Println("api key not found")
+			return
+		}
+		log.Fatalf("could not revoke api key: %v", err)
+	}
+	fmt.Printf("revoked api key: %s\n", id)
+}
+
+// targetFlags parses repeated -target flags into targeting rules.
+type targetFlags []*proto.TargetingRule
+
+func (t *targetFlags) String() string {
+	return fmt.Sprintf("%d rules", len(*t))
+}
+
+func (t *targetFlags) Set(value string) error {
+	conditions, destination, ok := strings.Cut(value, "@")
+	if !ok {
+		return fmt.Errorf("expected conditions@url")
+	}
+	rule := &proto.TargetingRule{Destination: destination}
+	for _, condition := range strings.Split(conditions, ",") {
+		key, val, _ := strings.Cut(condition, "=")
+		switch key {
+		case "os":
+			rule.Os = append(rule.Os, val)
+		case "device":
+			rule.Devices = append(rule.Devices, val)
+		case "lang":
+			rule.Languages = append(rule.Languages, val)
+		case "country":
+			rule.Countries = append(rule.Countries, val)
+		default:
+			return fmt.Errorf("unknown condition %q, expected os, device, lang or country", key)
+		}
+	}
+	*t = append(*t, rule)
+	return nil
+}
+
+// variantFlags parses repeated -variant flags into variants.
+type variantFlags []*proto.Variant
+
+func (v *variantFlags) String() string {
+	return fmt.Sprintf("%d variants", len(*v))
+}
+
+func (v *variantFlags) Set(value string) error {
+	spec, destination, ok := strings.Cut(value, "@")
+	if !ok {
+		return fmt.Errorf("expected [name:]weight@url")
+	}
+	variant := &proto.Variant{Destination: destination}
+	if name, weight, named := strings.Cut(spec, ":"); named {
+		variant.Name, spec = name, weight
+	}
+	weight, err := strconv.ParseInt(spec, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid weight %q", spec)
+	}
+	variant.Weight = int32(weight)
+	*v = append(*v, variant)
+	return nil
+}
@@ -5,16 +5,20 @@ import (
 	_ "embed"
 	"log"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 
 	"github.com/hypedn/mflag"
+	"github.com/ndajr/urlshortener-go/internal/analytics"
 	"github.com/ndajr/urlshortener-go/internal/cachestore"
 	"github.com/ndajr/urlshortener-go/internal/config"
 	"github.com/ndajr/urlshortener-go/internal/datastore"
+	"github.com/ndajr/urlshortener-go/internal/geoip"
 	"github.com/ndajr/urlshortener-go/internal/httpserver"
+	"github.com/ndajr/urlshortener-go/internal/policy"
 	"github.com/ndajr/urlshortener-go/internal/rpcserver"
 )
 
@@ -36,11 +40,14 @@ func main() {
 	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer shutdown()
 
-	appCfg, redisCfg, rlCfg := config.GetSettings()
+	appCfg, shortenerCfg, redisCfg, rlCfg, authCfg, analyticsCfg, err := config.GetSettings()
+	if err != nil {
+		log.Fatal(err)
+	}
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	logger.Info("starting urlshortener service", "version", version, "commit", gitCommit)
 
-	db, err := datastore.NewStore(ctx, logger, appCfg)
+	db, err := datastore.New(ctx, logger, appCfg)
 	if err != nil {
 		logger.Error("failed to connect to datastore", "error", err)
 		os.Exit(1)
@@ -56,14 +63,51 @@ func main() {
 
 	var wg sync.WaitGroup
 
-	grpcSrv := rpcserver.NewServer(logger, db, cache, &rlCfg)
+	recorder, err := analytics.NewRecorder(logger, db, analyticsCfg)
+	if err != nil {
+		logger.Error("invalid analytics settings", "error", err)
+		os.Exit(1)
+	}
+	recorder.Run(ctx, &wg)
+
+	// The destination policy is optional. Without it, links may point anywhere parseURL allows.
+	var destinations rpcserver.DestinationPolicy
+	if shortenerCfg.PolicyFile != "" {
+		engine, policyErr := policy.Load(logger, shortenerCfg.PolicyFile, shortenerCfg.PolicyReload)
+		if policyErr != nil {
+			logger.Error("failed to load destination policy", "error", policyErr)
+			os.Exit(1)
+		}
+		engine.Run(ctx, &wg)
+		destinations = engine
+	}
+
+	// Without resolving destinations, only those with an internal address for a host are rejected.
+	var resolver rpcserver.Resolver
+	if shortenerCfg.ResolveDestinations {
+		resolver = net.DefaultResolver
+	}
+
+	grpcSrv := rpcserver.NewServer(logger, db, cache, shortenerCfg, destinations, resolver, authCfg, &rlCfg, appCfg.TrustedProxies)
 	if runErr := grpcSrv.Run(ctx, appCfg.GrpcEndpoint, &wg); runErr != nil {
 		logger.Error("failed to run gRPC server", "error", runErr)
 		os.Exit(1)
 	}
 
+	// The country database is optional. Without it, targeting rules never match on countries.
+	var countries httpserver.CountryResolver
+	if appCfg.GeoIPDB != "" {
+		geoDB, geoErr := geoip.Open(appCfg.GeoIPDB)
+		if geoErr != nil {
+			logger.Error("failed to open GeoIP database", "error", geoErr)
+			os.Exit(1)
+		}
+		defer geoDB.Close()
+		countries = geoDB
+	}
+
 	gwmux := grpcSrv.NewGatewayMux()
-	httpSrv := httpserver.NewServer(grpcSrv, gwmux, logger, swaggerJSON)
+	httpSrv := httpserver.NewServer(grpcSrv, gwmux, recorder, countries, appCfg.TrustedProxies, appCfg.PublicURL, logger, swaggerJSON)
 	if runErr := httpSrv.Run(ctx, appCfg.HttpEndpoint, &wg); runErr != nil {
 		logger.Error("failed to run HTTP server", "error", runErr)
 		os.Exit(1)
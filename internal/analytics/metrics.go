@@ -0,0 +1,40 @@
+package analytics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// ReasonLabel is the label for dropped click metrics, representing why clicks were lost.
+	ReasonLabel = "reason"
+
+	// ReasonBufferFull is the label for clicks dropped because the in-memory buffer was full.
+	ReasonBufferFull = "buffer_full"
+	// ReasonWriteFailed is the label for clicks dropped because the batch write failed.
+	ReasonWriteFailed = "write_failed"
+)
+
+// Metrics contains the Prometheus collectors for click recording.
+type Metrics struct {
+	Written prometheus.Counter
+	Dropped *prometheus.CounterVec
+}
+
+// NewMetrics creates and registers the click recording metrics collectors.
+func NewMetrics() Metrics {
+	m := Metrics{
+		Written: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "clicks_written_total",
+			Help: "The total number of clicks written to the database.",
+		}),
+		Dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "clicks_dropped_total",
+			Help: "The total number of clicks that could not be recorded.",
+		}, []string{ReasonLabel}),
+	}
+	prometheus.MustRegister(
+		m.Written,
+		m.Dropped,
+	)
+	return m
+}
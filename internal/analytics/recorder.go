@@ -0,0 +1,122 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/config"
+	"github.com/ndajr/urlshortener-go/internal/core"
+)
+
+// flushTimeout bounds a single batch write, so a slow database cannot stall the recorder.
+const flushTimeout = 5 * time.Second
+
+// ClickStore persists batches of clicks.
+type ClickStore interface {
+	AddClicks(ctx context.Context, clicks []core.Click) error
+}
+
+// Recorder buffers clicks in memory and writes them to the store in batches,
+// so that recording a click never adds latency to the redirect path.
+type Recorder struct {
+	logger  *slog.Logger
+	store   ClickStore
+	cfg     config.Analytics
+	clicks  chan core.Click
+	metrics Metrics
+}
+
+// NewRecorder returns a recorder writing to store. The buffer and batch sizes and the flush interval
+// must be positive.
+func NewRecorder(logger *slog.Logger, store ClickStore, cfg config.Analytics) (*Recorder, error) {
+	switch {
+	case cfg.BufferSize <= 0:
+		return nil, fmt.Errorf("analytics: click buffer size must be positive, got %d", cfg.BufferSize)
+	case cfg.BatchSize <= 0:
+		return nil, fmt.Errorf("analytics: click batch size must be positive, got %d", cfg.BatchSize)
+	case cfg.FlushInterval <= 0:
+		return nil, fmt.Errorf("analytics: click flush interval must be positive, got %s", cfg.FlushInterval)
+	}
+	return &Recorder{
+		logger:  logger,
+		store:   store,
+		cfg:     cfg,
+		clicks:  make(chan core.Click, cfg.BufferSize),
+		metrics: NewMetrics(),
+	}, nil
+}
+
+// Record queues a click without blocking. If the buffer is full, the click is dropped.
+func (r *Recorder) Record(click core.Click) {
+	select {
+	case r.clicks <- click:
+	default:
+		r.metrics.Dropped.WithLabelValues(ReasonBufferFull).Inc()
+	}
+}
+
+// Run starts writing queued clicks in the background until ctx is cancelled,
+// at which point the remaining clicks are flushed before returning.
+func (r *Recorder) Run(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(r.cfg.FlushInterval)
+		defer ticker.Stop()
+
+		batch := make([]core.Click, 0, r.cfg.BatchSize)
+		for {
+			select {
+			case click := <-r.clicks:
+				batch = append(batch, click)
+				if len(batch) >= r.cfg.BatchSize {
+					batch = r.flush(ctx, batch)
+				}
+			case <-ticker.C:
+				batch = r.flush(ctx, batch)
+			case <-ctx.Done():
+				r.logger.Info("click recorder shutting down")
+				r.drain(batch)
+				return
+			}
+		}
+	}()
+}
+
+// drain flushes the given batch and everything still queued, using a fresh context
+// since the one passed to Run is already cancelled.
+func (r *Recorder) drain(batch []core.Click) {
+	ctx := context.Background()
+	for {
+		select {
+		case click := <-r.clicks:
+			batch = append(batch, click)
+			if len(batch) >= r.cfg.BatchSize {
+				batch = r.flush(ctx, batch)
+			}
+		default:
+			r.flush(ctx, batch)
+			return
+		}
+	}
+}
+
+// flush writes the batch to the store and returns it emptied for reuse.
+func (r *Recorder) flush(ctx context.Context, batch []core.Click) []core.Click {
+	if len(batch) == 0 {
+		return batch
+	}
+	ctx, cancel := context.WithTimeout(ctx, flushTimeout)
+	defer cancel()
+
+	if err := r.store.AddClicks(ctx, batch); err != nil {
+		r.logger.Error("failed to write clicks", "count", len(batch), "error", err)
+		r.metrics.Dropped.WithLabelValues(ReasonWriteFailed).Add(float64(len(batch)))
+	} else {
+		r.metrics.Written.Add(float64(len(batch)))
+	}
+	return batch[:0]
+}
@@ -0,0 +1,45 @@
+package analytics
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecorder(t *testing.T) {
+	valid := config.Analytics{BufferSize: 100, BatchSize: 10, FlushInterval: time.Second}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name    string
+		cfg     func(cfg config.Analytics) config.Analytics
+		wantErr string
+	}{
+		{
+			name:    "NewRecorder/failure_without_flush_interval",
+			cfg:     func(cfg config.Analytics) config.Analytics { cfg.FlushInterval = 0; return cfg },
+			wantErr: "click flush interval must be positive",
+		},
+		{
+			name:    "NewRecorder/failure_on_negative_batch_size",
+			cfg:     func(cfg config.Analytics) config.Analytics { cfg.BatchSize = -1; return cfg },
+			wantErr: "click batch size must be positive",
+		},
+		{
+			name:    "NewRecorder/failure_without_buffer",
+			cfg:     func(cfg config.Analytics) config.Analytics { cfg.BufferSize = 0; return cfg },
+			wantErr: "click buffer size must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRecorder(logger, nil, tt.cfg(valid))
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
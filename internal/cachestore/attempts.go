@@ -0,0 +1,133 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Prefixes namespacing the attempt counters of each AttemptThrottle in Redis.
+const (
+	PasswordAttemptsPrefix     = "password_attempts:"
+	LinkPasswordFailuresPrefix = "password_failures:"
+	AuthFailuresPrefix         = "auth_failures:"
+)
+
+// attemptScript counts an attempt in a fixed window, which starts with the first attempt.
+const attemptScript = `
+	local attempts = redis.call('INCR', KEYS[1])
+	if attempts == 1 then
+		redis.call('PEXPIRE', KEYS[1], ARGV[1])
+	end
+	return attempts
+`
+
+// AttemptThrottle limits attempts at something that can be guessed, e.g. the password of a protected link
+// per short code and client, or API keys per client. Counters live in Redis, so every replica shares them,
+// or in process memory when there is no cache.
+type AttemptThrottle struct {
+	rdb         *redis.Client
+	prefix      string
+	maxAttempts int
+	window      time.Duration
+
+	mu        sync.Mutex
+	local     map[string]attemptWindow
+	lastSweep time.Time
+}
+
+type attemptWindow struct {
+	attempts  int
+	expiresAt time.Time
+}
+
+// NewAttemptThrottle allows maxAttempts attempts per key until a successful one, or until window
+// has passed since the first attempt. Its counters are kept under prefix. The cache is optional.
+func NewAttemptThrottle(cache *Cache, prefix string, maxAttempts int, window time.Duration) *AttemptThrottle {
+	t := &AttemptThrottle{
+		prefix:      prefix,
+		maxAttempts: maxAttempts,
+		window:      window,
+		local:       make(map[string]attemptWindow),
+		lastSweep:   time.Now(),
+	}
+	if cache != nil {
+		t.rdb = cache.rdb
+	}
+	return t
+}
+
+// Attempt counts an attempt for key, and reports whether it may go ahead.
+// Attempts made while blocked are counted too, but do not extend the window.
+func (t *AttemptThrottle) Attempt(ctx context.Context, key string) (bool, error) {
+	if t.rdb == nil {
+		return t.attemptLocal(key), nil
+	}
+	attempts, err := t.rdb.Eval(ctx, attemptScript, []string{t.prefix + key}, t.window.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return attempts <= t.maxAttempts, nil
+}
+
+// Blocked reports whether key has used up its attempts, without counting one. It lets callers turn
+// away a blocked key before doing the work an attempt costs, and only count the attempts that fail.
+func (t *AttemptThrottle) Blocked(ctx context.Context, key string) (bool, error) {
+	if t.rdb == nil {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		w, ok := t.local[key]
+		return ok && time.Now().Before(w.expiresAt) && w.attempts >= t.maxAttempts, nil
+	}
+	attempts, err := t.rdb.Get(ctx, t.prefix+key).Int()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return attempts >= t.maxAttempts, nil
+}
+
+// Reset forgets the attempts made for key, after a successful one.
+func (t *AttemptThrottle) Reset(ctx context.Context, key string) error {
+	if t.rdb == nil {
+		t.mu.Lock()
+		delete(t.local, key)
+		t.mu.Unlock()
+		return nil
+	}
+	return t.rdb.Del(ctx, t.prefix+key).Err()
+}
+
+func (t *AttemptThrottle) attemptLocal(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	w, ok := t.local[key]
+	if !ok || !now.Before(w.expiresAt) {
+		w = attemptWindow{expiresAt: now.Add(t.window)}
+	}
+	w.attempts++
+	t.local[key] = w
+	t.sweep(now)
+	return w.attempts <= t.maxAttempts
+}
+
+// sweep drops the windows that have passed, at most once per window, so clients that never
+// come back are not kept forever. It must be called with the lock held.
+func (t *AttemptThrottle) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.window {
+		return
+	}
+	t.lastSweep = now
+	for key, w := range t.local {
+		if !now.Before(w.expiresAt) {
+			delete(t.local, key)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package cachestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttemptThrottle(t *testing.T) {
+	ctx := context.Background()
+
+	throttles := map[string]func(t *testing.T) *AttemptThrottle{
+		"redis": func(t *testing.T) *AttemptThrottle {
+			mr := miniredis.RunT(t)
+			rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = rdb.Close() })
+			return NewAttemptThrottle(&Cache{rdb: rdb}, PasswordAttemptsPrefix, 2, time.Minute)
+		},
+		"local": func(t *testing.T) *AttemptThrottle {
+			return NewAttemptThrottle(nil, PasswordAttemptsPrefix, 2, time.Minute)
+		},
+	}
+
+	tests := []struct {
+		name   string
+		assert func(t *testing.T, throttle *AttemptThrottle)
+	}{
+		{
+			name: "AttemptThrottle/failure_after_max_attempts",
+			assert: func(t *testing.T, throttle *AttemptThrottle) {
+				for _, want := range []bool{true, true, false} {
+					allowed, err := throttle.Attempt(ctx, "code:203.0.113.7")
+					require.NoError(t, err)
+					require.Equal(t, want, allowed)
+				}
+				allowed, err := throttle.Attempt(ctx, "code:203.0.113.8")
+				require.NoError(t, err)
+				require.True(t, allowed)
+			},
+		},
+		{
+			name: "AttemptThrottle/success_blocking_without_counting",
+			assert: func(t *testing.T, throttle *AttemptThrottle) {
+				for range 5 {
+					blocked, err := throttle.Blocked(ctx, "code")
+					require.NoError(t, err)
+					require.False(t, blocked)
+				}
+				for range 2 {
+					_, err := throttle.Attempt(ctx, "code")
+					require.NoError(t, err)
+				}
+				blocked, err := throttle.Blocked(ctx, "code")
+				require.NoError(t, err)
+				require.True(t, blocked)
+			},
+		},
+		{
+			name: "AttemptThrottle/success_after_reset",
+			assert: func(t *testing.T, throttle *AttemptThrottle) {
+				for range 2 {
+					_, err := throttle.Attempt(ctx, "code")
+					require.NoError(t, err)
+				}
+				require.NoError(t, throttle.Reset(ctx, "code"))
+				blocked, err := throttle.Blocked(ctx, "code")
+				require.NoError(t, err)
+				require.False(t, blocked)
+			},
+		},
+	}
+
+	for backend, newThrottle := range throttles {
+		for _, tt := range tests {
+			t.Run(tt.name+"/"+backend, func(t *testing.T) {
+				tt.assert(t, newThrottle(t))
+			})
+		}
+	}
+}
@@ -2,12 +2,14 @@ package cachestore
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/ndajr/urlshortener-go/internal/config"
+	"github.com/ndajr/urlshortener-go/internal/core"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -19,6 +21,11 @@ type Cache struct {
 	metrics Metrics
 	logger  *slog.Logger
 	cfg     config.Redis
+
+	// local is an optional in-process tier in front of Redis. Evictions are broadcast
+	// to other replicas over pubsub; missed messages are bounded by the local TTL.
+	local  *localCache
+	pubsub *redis.PubSub
 }
 
 func NewCache(ctx context.Context, logger *slog.Logger, cfg config.Redis) (*Cache, error) {
@@ -53,6 +60,17 @@ func NewCache(ctx context.Context, logger *slog.Logger, cfg config.Redis) (*Cach
 		logger.Warn("could not set redis maxmemory-policy to allkeys-lfu, ensure it is configured on the server", "error", err)
 	}
 
+	if cfg.LocalSize > 0 {
+		c.local = newLocalCache(cfg.LocalSize, cfg.LocalTTL)
+		// The subscription outlives NewCache, so it must not use the connect timeout context.
+		c.pubsub = rdb.Subscribe(context.Background(), c.invalidationChannel())
+		if _, err := c.pubsub.Receive(ctx); err != nil {
+			_ = c.pubsub.Close()
+			return &Cache{}, fmt.Errorf("cache: failed to subscribe to invalidations: %w", err)
+		}
+		go c.receiveInvalidations()
+	}
+
 	return c, nil
 }
 
@@ -78,25 +96,151 @@ func (c Cache) Ping(ctx context.Context) error {
 	return nil
 }
 
-// GetURL retrieves an URL from the cache. It returns redis.Nil if the key does not exist.
-func (c Cache) GetURL(ctx context.Context, key string) (string, error) {
+// GetURL retrieves an URL from the cache, checking the in-process tier before Redis.
+// It returns redis.Nil if the key does not exist.
+func (c Cache) GetURL(ctx context.Context, key string) (core.URL, error) {
+	if c.local != nil {
+		if url, ok := c.local.get(key); ok {
+			c.metrics.LocalHits.WithLabelValues(c.cfg.UrlPrefix).Inc()
+			return url, nil
+		}
+		c.metrics.LocalMisses.WithLabelValues(c.cfg.UrlPrefix).Inc()
+	}
+
 	// Use GETEX to retrieve the value and reset the TTL in one atomic operation.
 	// This implements a "sliding expiration" policy, ensuring that frequently
 	// accessed URLs remain in the cache. This command requires Redis v6.2+.
-	val, err := c.rdb.GetEx(ctx, c.toInternalKey(key), c.cfg.UrlTTL).Result()
+	internalKey := c.toInternalKey(key)
+	val, err := c.rdb.GetEx(ctx, internalKey, c.cfg.UrlTTL).Bytes()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			c.metrics.Misses.WithLabelValues(c.cfg.UrlPrefix).Inc()
 		}
-		return "", err
+		return core.URL{}, err
+	}
+
+	var url core.URL
+	if err := json.Unmarshal(val, &url); err != nil {
+		// Entries in an unknown format are treated as a miss, so they get overwritten on reload.
+		c.logger.Warn("failed to decode cached url", "key", key, "error", err)
+		c.metrics.Misses.WithLabelValues(c.cfg.UrlPrefix).Inc()
+		return core.URL{}, redis.Nil
 	}
 	c.metrics.Hits.WithLabelValues(c.cfg.UrlPrefix).Inc()
-	return val, nil
+
+	// The sliding expiration above must not keep an entry alive past the link's own expiry or start.
+	if ttl := c.ttl(url); ttl < c.cfg.UrlTTL {
+		if err := c.rdb.PExpire(ctx, internalKey, ttl).Err(); err != nil {
+			c.logger.Warn("failed to bound cache ttl to url expiry or start", "key", key, "error", err)
+		}
+	}
+	c.setLocal(url)
+	return url, nil
 }
 
-// SetURL adds a key-value pair to the cache.
-func (c Cache) SetURL(ctx context.Context, key string, value string) error {
-	return c.rdb.Set(ctx, c.toInternalKey(key), value, c.cfg.UrlTTL).Err()
+// setURLScript caches an URL, unless it was evicted since its version was read.
+// KEYS[1] is the URL key and KEYS[2] its version key; ARGV holds the encoded URL, its TTL in milliseconds
+// and the version read before the URL was loaded. A missing version key is version 0.
+const setURLScript = `
+	local version = tonumber(redis.call('GET', KEYS[2])) or 0
+	if version ~= tonumber(ARGV[3]) then
+		return 0
+	end
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+`
+
+// Version returns the number of times an URL was evicted, to be read before loading it from the database
+// and passed on to SetURL. It is 0 if the URL was never evicted, or not for longer than the URL TTL.
+func (c Cache) Version(ctx context.Context, key string) (int64, error) {
+	version, err := c.rdb.Get(ctx, c.versionKey(key)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// SetURL adds an URL loaded from the database to the cache, unless it was evicted since version was read,
+// so a load racing with a change cannot put back the URL as it was before. URLs that expire or go live later
+// are cached no longer than until then. Protected URLs are not cached at all, as only the database can check
+// their password.
+func (c Cache) SetURL(ctx context.Context, url core.URL, version int64) error {
+	ttl := c.ttl(url)
+	if ttl <= 0 || url.Protected() {
+		return nil
+	}
+	val, err := json.Marshal(url)
+	if err != nil {
+		return fmt.Errorf("cache: failed to encode url: %w", err)
+	}
+	keys := []string{c.toInternalKey(url.ShortCode), c.versionKey(url.ShortCode)}
+	set, err := c.rdb.Eval(ctx, setURLScript, keys, val, ttl.Milliseconds(), version).Int()
+	if err != nil {
+		return err
+	}
+	if set == 1 {
+		c.setLocal(url)
+	}
+	return nil
+}
+
+// DeleteURL evicts an URL and its click counter from the cache, bumps its version so loads started before
+// are not cached, and tells other replicas to drop their local copy. Deleting a missing key is not an error.
+func (c Cache) DeleteURL(ctx context.Context, key string) error {
+	if c.local != nil {
+		c.local.delete(key)
+		c.metrics.LocalSize.WithLabelValues(c.cfg.UrlPrefix).Set(float64(c.local.len()))
+	}
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, c.versionKey(key))
+		// The version only has to outlive the loads in flight, so it expires like the URLs do.
+		pipe.PExpire(ctx, c.versionKey(key), c.cfg.UrlTTL)
+		pipe.Del(ctx, c.toInternalKey(key), c.clicksKey(key))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if c.local != nil {
+		return c.rdb.Publish(ctx, c.invalidationChannel(), key).Err()
+	}
+	return nil
+}
+
+func (c Cache) setLocal(url core.URL) {
+	if c.local == nil {
+		return
+	}
+	c.local.set(url.ShortCode, url, c.ttl(url))
+	c.metrics.LocalSize.WithLabelValues(c.cfg.UrlPrefix).Set(float64(c.local.len()))
+}
+
+// receiveInvalidations evicts local entries published by other replicas, until the subscription is closed.
+func (c Cache) receiveInvalidations() {
+	for msg := range c.pubsub.Channel() {
+		c.local.delete(msg.Payload)
+	}
+}
+
+func (c Cache) invalidationChannel() string {
+	return c.cfg.UrlPrefix + ":invalidate"
+}
+
+// ttl returns how long an URL, or its click counter, may stay in the cache: no longer than until the URL
+// expires or goes live, so it is read again from the database at either boundary.
+func (c Cache) ttl(url core.URL) time.Duration {
+	ttl := c.cfg.UrlTTL
+	if url.ExpiresAt != nil {
+		ttl = min(ttl, time.Until(*url.ExpiresAt))
+	}
+	if url.Pending(time.Now()) {
+		ttl = min(ttl, time.Until(*url.NotBefore))
+	}
+	return ttl
+}
+
+func (c Cache) versionKey(key string) string {
+	return c.toInternalKey("version:" + key)
 }
 
 func (c Cache) toInternalKey(s string) string {
@@ -104,5 +248,8 @@ func (c Cache) toInternalKey(s string) string {
 }
 
 func (c Cache) Close() {
+	if c.pubsub != nil {
+		_ = c.pubsub.Close()
+	}
 	_ = c.rdb.Close()
 }
@@ -0,0 +1,172 @@
+package cachestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ndajr/urlshortener-go/internal/config"
+	"github.com/ndajr/urlshortener-go/internal/core"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+const testURLTTL = time.Hour
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		assert func(t *testing.T, mr *miniredis.Miniredis)
+	}{
+		{
+			name: "TTL/success_bounding_to_expires_at",
+			assert: func(t *testing.T, mr *miniredis.Miniredis) {
+				cache := newTestCache(t, mr, 0)
+				url := core.URL{ShortCode: "expiring", LongURL: "https://example.com/", ExpiresAt: ptr(time.Now().Add(10 * time.Minute))}
+				require.InDelta(t, 10*time.Minute, cache.ttl(url), float64(time.Second))
+
+				require.NoError(t, cache.SetURL(ctx, url, 0))
+				require.InDelta(t, 10*time.Minute, mr.TTL("url:expiring"), float64(time.Second))
+			},
+		},
+		{
+			name: "TTL/success_bounding_to_not_before",
+			assert: func(t *testing.T, mr *miniredis.Miniredis) {
+				cache := newTestCache(t, mr, 0)
+				url := core.URL{
+					ShortCode: "scheduled",
+					LongURL:   "https://example.com/",
+					NotBefore: ptr(time.Now().Add(5 * time.Minute)),
+					ExpiresAt: ptr(time.Now().Add(10 * time.Minute)),
+				}
+				require.InDelta(t, 5*time.Minute, cache.ttl(url), float64(time.Second))
+
+				require.NoError(t, cache.SetURL(ctx, url, 0))
+				require.InDelta(t, 5*time.Minute, mr.TTL("url:scheduled"), float64(time.Second))
+
+				// Once live, only the expiry bounds it.
+				url.NotBefore = ptr(time.Now().Add(-time.Minute))
+				require.InDelta(t, 10*time.Minute, cache.ttl(url), float64(time.Second))
+				url.ExpiresAt = nil
+				require.Equal(t, testURLTTL, cache.ttl(url))
+			},
+		},
+		{
+			name: "TTL/success_keeping_bound_on_sliding_expiration",
+			assert: func(t *testing.T, mr *miniredis.Miniredis) {
+				cache := newTestCache(t, mr, 0)
+				url := core.URL{ShortCode: "sliding", LongURL: "https://example.com/", ExpiresAt: ptr(time.Now().Add(10 * time.Minute))}
+				require.NoError(t, cache.SetURL(ctx, url, 0))
+
+				got, err := cache.GetURL(ctx, "sliding")
+				require.NoError(t, err)
+				require.Equal(t, url.LongURL, got.LongURL)
+				require.InDelta(t, 10*time.Minute, mr.TTL("url:sliding"), float64(time.Second))
+			},
+		},
+		{
+			name: "TTL/success_skipping_expired_and_protected",
+			assert: func(t *testing.T, mr *miniredis.Miniredis) {
+				cache := newTestCache(t, mr, 10)
+				expired := core.URL{ShortCode: "expired", LongURL: "https://example.com/", ExpiresAt: ptr(time.Now().Add(-time.Minute))}
+				protected := core.URL{ShortCode: "protected", LongURL: "https://example.com/", PasswordHash: "$2a$10$hash"}
+				for _, url := range []core.URL{expired, protected} {
+					require.NoError(t, cache.SetURL(ctx, url, 0))
+					require.False(t, mr.Exists("url:"+url.ShortCode), url.ShortCode)
+				}
+				require.Zero(t, cache.local.len())
+			},
+		},
+		{
+			name: "TTL/success_bounding_local_tier",
+			assert: func(t *testing.T, mr *miniredis.Miniredis) {
+				cache := newTestCache(t, mr, 10)
+				url := core.URL{ShortCode: "short-lived", LongURL: "https://example.com/", ExpiresAt: ptr(time.Now().Add(50 * time.Millisecond))}
+				require.NoError(t, cache.SetURL(ctx, url, 0))
+				_, ok := cache.local.get("short-lived")
+				require.True(t, ok)
+
+				time.Sleep(60 * time.Millisecond)
+				_, ok = cache.local.get("short-lived")
+				require.False(t, ok)
+			},
+		},
+		{
+			name: "Version/failure_caching_url_evicted_since_loaded",
+			assert: func(t *testing.T, mr *miniredis.Miniredis) {
+				loader, changer := newTestCache(t, mr, 10), newTestCache(t, mr, 10)
+				version, err := loader.Version(ctx, "racy")
+				require.NoError(t, err)
+				stale := core.URL{ShortCode: "racy", LongURL: "https://example.com/before"}
+
+				// The URL changes, and is evicted, after the loader read it but before it cached it.
+				require.NoError(t, changer.DeleteURL(ctx, "racy"))
+				require.NoError(t, loader.SetURL(ctx, stale, version))
+				require.False(t, mr.Exists("url:racy"))
+				_, ok := loader.local.get("racy")
+				require.False(t, ok)
+
+				// Loads started after the change are cached.
+				version, err = loader.Version(ctx, "racy")
+				require.NoError(t, err)
+				require.Equal(t, int64(1), version)
+				require.InDelta(t, testURLTTL, mr.TTL("url:version:racy"), float64(time.Second))
+				require.NoError(t, loader.SetURL(ctx, core.URL{ShortCode: "racy", LongURL: "https://example.com/after"}, version))
+				got, err := loader.GetURL(ctx, "racy")
+				require.NoError(t, err)
+				require.Equal(t, "https://example.com/after", got.LongURL)
+			},
+		},
+		{
+			name: "Invalidation/success_evicting_local_copies_of_other_replicas",
+			assert: func(t *testing.T, mr *miniredis.Miniredis) {
+				replicaA, replicaB := newTestCache(t, mr, 10), newTestCache(t, mr, 10)
+				url := core.URL{ShortCode: "shared", LongURL: "https://example.com/"}
+				require.NoError(t, replicaA.SetURL(ctx, url, 0))
+
+				_, err := replicaB.GetURL(ctx, "shared")
+				require.NoError(t, err)
+				_, ok := replicaB.local.get("shared")
+				require.True(t, ok)
+
+				require.NoError(t, replicaA.DeleteURL(ctx, "shared"))
+				_, ok = replicaA.local.get("shared")
+				require.False(t, ok)
+				require.Eventually(t, func() bool {
+					_, ok := replicaB.local.get("shared")
+					return !ok
+				}, time.Second, 10*time.Millisecond)
+				_, err = replicaB.GetURL(ctx, "shared")
+				require.ErrorIs(t, err, redis.Nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assert(t, miniredis.RunT(t))
+		})
+	}
+}
+
+// newTestCache connects a cache to mr, with an in-process tier of localSize URLs if it is not zero.
+func newTestCache(t *testing.T, mr *miniredis.Miniredis, localSize int) *Cache {
+	t.Helper()
+	cache, err := NewCache(context.Background(), discardLogger, config.Redis{
+		Addr:      mr.Addr(),
+		UrlPrefix: "url",
+		UrlTTL:    testURLTTL,
+		LocalSize: localSize,
+		LocalTTL:  time.Minute,
+	})
+	require.NoError(t, err)
+	t.Cleanup(cache.Close)
+	return cache
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
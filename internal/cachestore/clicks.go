@@ -0,0 +1,64 @@
+package cachestore
+
+import (
+	"context"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	"github.com/redis/go-redis/v9"
+)
+
+// takeClickScript takes a click off the counter of a click-limited link, unless it has none left.
+// It returns the clicks left before this one, or -1 if there is no counter.
+const takeClickScript = `
+	local left = redis.call('GET', KEYS[1])
+	if not left then
+		return -1
+	end
+	left = tonumber(left)
+	if left > 0 then
+		redis.call('DECR', KEYS[1])
+	end
+	return left
+`
+
+// TakeClick takes a click off the shared counter of a click-limited link, and returns the number of
+// clicks it had left before. Zero means the link is used up. It returns redis.Nil if there is no counter,
+// e.g. after it was evicted, for the caller to count the click in the database and seed a new one.
+func (c Cache) TakeClick(ctx context.Context, key string) (int, error) {
+	left, err := c.rdb.Eval(ctx, takeClickScript, []string{c.clicksKey(key)}).Int()
+	if err != nil {
+		return 0, err
+	}
+	if left < 0 {
+		return 0, redis.Nil
+	}
+	return left, nil
+}
+
+// returnClickScript puts back a click taken off the counter of a click-limited link, unless the counter is gone.
+const returnClickScript = `
+	if redis.call('EXISTS', KEYS[1]) == 1 then
+		redis.call('INCR', KEYS[1])
+	end
+	return 0
+`
+
+// ReturnClick puts back a click taken with TakeClick that the database did not count, e.g. as it failed,
+// so the click is not lost. Counters evicted in between are seeded from the database again instead.
+func (c Cache) ReturnClick(ctx context.Context, key string) error {
+	return c.rdb.Eval(ctx, returnClickScript, []string{c.clicksKey(key)}).Err()
+}
+
+// SeedClicks starts the counter of a click-limited link at the number of clicks it has left in the database,
+// unless another replica already did. The counter is kept as long as the link itself would be.
+func (c Cache) SeedClicks(ctx context.Context, url core.URL, left int) error {
+	ttl := c.ttl(url)
+	if ttl <= 0 {
+		return nil
+	}
+	return c.rdb.SetNX(ctx, c.clicksKey(url.ShortCode), left, ttl).Err()
+}
+
+func (c Cache) clicksKey(key string) string {
+	return c.toInternalKey("clicks:" + key)
+}
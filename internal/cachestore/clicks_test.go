@@ -0,0 +1,75 @@
+package cachestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ndajr/urlshortener-go/internal/core"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClicks(t *testing.T) {
+	ctx := context.Background()
+	url := core.URL{ShortCode: "limited", LongURL: "https://example.com/", MaxClicks: 2}
+
+	tests := []struct {
+		name   string
+		assert func(t *testing.T, cache *Cache, mr *miniredis.Miniredis)
+	}{
+		{
+			name: "Clicks/success_taking_until_used_up",
+			assert: func(t *testing.T, cache *Cache, mr *miniredis.Miniredis) {
+				require.NoError(t, cache.SeedClicks(ctx, url, 2))
+				require.InDelta(t, testURLTTL, mr.TTL("url:clicks:limited"), float64(time.Second))
+				for _, want := range []int{2, 1, 0, 0} {
+					left, err := cache.TakeClick(ctx, "limited")
+					require.NoError(t, err)
+					require.Equal(t, want, left)
+				}
+			},
+		},
+		{
+			name: "Clicks/success_keeping_counter_seeded_first",
+			assert: func(t *testing.T, cache *Cache, mr *miniredis.Miniredis) {
+				require.NoError(t, cache.SeedClicks(ctx, url, 1))
+				require.NoError(t, cache.SeedClicks(ctx, url, 2))
+				left, err := cache.TakeClick(ctx, "limited")
+				require.NoError(t, err)
+				require.Equal(t, 1, left)
+			},
+		},
+		{
+			name: "Clicks/success_returning_click",
+			assert: func(t *testing.T, cache *Cache, mr *miniredis.Miniredis) {
+				require.NoError(t, cache.SeedClicks(ctx, url, 1))
+				_, err := cache.TakeClick(ctx, "limited")
+				require.NoError(t, err)
+				require.NoError(t, cache.ReturnClick(ctx, "limited"))
+				left, err := cache.TakeClick(ctx, "limited")
+				require.NoError(t, err)
+				require.Equal(t, 1, left)
+			},
+		},
+		{
+			name: "Clicks/failure_without_counter",
+			assert: func(t *testing.T, cache *Cache, mr *miniredis.Miniredis) {
+				_, err := cache.TakeClick(ctx, "limited")
+				require.ErrorIs(t, err, redis.Nil)
+
+				// Returning a click does not bring back an evicted counter, which must be seeded from the database.
+				require.NoError(t, cache.ReturnClick(ctx, "limited"))
+				require.False(t, mr.Exists("url:clicks:limited"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			tt.assert(t, newTestCache(t, mr, 0), mr)
+		})
+	}
+}
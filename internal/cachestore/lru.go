@@ -0,0 +1,102 @@
+package cachestore
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+)
+
+// localCache is a size and TTL bounded LRU cache of URLs kept in process memory.
+// It sits in front of Redis to serve the hottest links without a network round trip.
+type localCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	// order holds *localEntry values, most recently used first.
+	order *list.List
+}
+
+type localEntry struct {
+	key       string
+	url       core.URL
+	expiresAt time.Time
+}
+
+func newLocalCache(capacity int, ttl time.Duration) *localCache {
+	return &localCache{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// get returns the URL cached for key, if present and not expired.
+func (l *localCache) get(key string) (core.URL, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[key]
+	if !ok {
+		return core.URL{}, false
+	}
+	entry := elem.Value.(*localEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		l.remove(elem)
+		return core.URL{}, false
+	}
+	l.order.MoveToFront(elem)
+	return entry.url, true
+}
+
+// set caches the URL for at most maxTTL, or the cache's own TTL if it is shorter,
+// evicting the least recently used entry when the cache is full.
+func (l *localCache) set(key string, url core.URL, maxTTL time.Duration) {
+	ttl := min(l.ttl, maxTTL)
+	if ttl <= 0 {
+		return
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[key]; ok {
+		entry := elem.Value.(*localEntry)
+		entry.url = url
+		entry.expiresAt = expiresAt
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	if l.order.Len() >= l.capacity {
+		l.remove(l.order.Back())
+	}
+	l.entries[key] = l.order.PushFront(&localEntry{key: key, url: url, expiresAt: expiresAt})
+}
+
+// delete evicts the entry for key, if any.
+func (l *localCache) delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[key]; ok {
+		l.remove(elem)
+	}
+}
+
+// len returns the number of cached entries, including expired ones not yet evicted.
+func (l *localCache) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+// remove must be called with the lock held.
+func (l *localCache) remove(elem *list.Element) {
+	entry := l.order.Remove(elem).(*localEntry)
+	delete(l.entries, entry.key)
+}
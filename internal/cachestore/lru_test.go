@@ -0,0 +1,100 @@
+package cachestore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalCache(t *testing.T) {
+	tests := []struct {
+		name   string
+		assert func(t *testing.T, local *localCache)
+	}{
+		{
+			name: "LocalCache/success_evicting_least_recently_used",
+			assert: func(t *testing.T, local *localCache) {
+				for _, code := range []string{"a", "b", "c"} {
+					local.set(code, core.URL{ShortCode: code}, time.Hour)
+				}
+				// Reading a makes b the least recently used.
+				_, ok := local.get("a")
+				require.True(t, ok)
+
+				local.set("d", core.URL{ShortCode: "d"}, time.Hour)
+				require.Equal(t, 3, local.len())
+				_, ok = local.get("b")
+				require.False(t, ok)
+				for _, code := range []string{"a", "c", "d"} {
+					url, ok := local.get(code)
+					require.True(t, ok, code)
+					require.Equal(t, code, url.ShortCode)
+				}
+			},
+		},
+		{
+			name: "LocalCache/success_replacing_entry_without_evicting",
+			assert: func(t *testing.T, local *localCache) {
+				for _, code := range []string{"a", "b", "c"} {
+					local.set(code, core.URL{ShortCode: code, LongURL: "https://example.com/" + code}, time.Hour)
+				}
+				local.set("a", core.URL{ShortCode: "a", LongURL: "https://example.com/a2"}, time.Hour)
+				require.Equal(t, 3, local.len())
+
+				url, ok := local.get("a")
+				require.True(t, ok)
+				require.Equal(t, "https://example.com/a2", url.LongURL)
+				_, ok = local.get("b")
+				require.True(t, ok)
+			},
+		},
+		{
+			name: "LocalCache/success_expiring_after_ttl",
+			assert: func(t *testing.T, local *localCache) {
+				local.ttl = 20 * time.Millisecond
+				local.set("a", core.URL{ShortCode: "a"}, time.Hour)
+				_, ok := local.get("a")
+				require.True(t, ok)
+
+				time.Sleep(30 * time.Millisecond)
+				_, ok = local.get("a")
+				require.False(t, ok)
+				require.Zero(t, local.len())
+			},
+		},
+		{
+			name: "LocalCache/success_bounding_ttl_to_url",
+			assert: func(t *testing.T, local *localCache) {
+				local.set("a", core.URL{ShortCode: "a"}, 20*time.Millisecond)
+				time.Sleep(30 * time.Millisecond)
+				_, ok := local.get("a")
+				require.False(t, ok)
+
+				// URLs that are already expired are not cached at all.
+				local.set("b", core.URL{ShortCode: "b"}, -time.Second)
+				_, ok = local.get("b")
+				require.False(t, ok)
+				require.Zero(t, local.len())
+			},
+		},
+		{
+			name: "LocalCache/success_deleting",
+			assert: func(t *testing.T, local *localCache) {
+				local.set("a", core.URL{ShortCode: "a"}, time.Hour)
+				local.delete("a")
+				local.delete("missing")
+				_, ok := local.get("a")
+				require.False(t, ok)
+				require.Zero(t, local.len())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assert(t, newLocalCache(3, time.Minute))
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package cachestore
 
 import (
+	"errors"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -14,10 +16,14 @@ type Metrics struct {
 	Hits   *prometheus.CounterVec
 	Misses *prometheus.CounterVec
 	Size   *prometheus.GaugeVec
+
+	LocalHits   *prometheus.CounterVec
+	LocalMisses *prometheus.CounterVec
+	LocalSize   *prometheus.GaugeVec
 }
 
-// NewMetrics creates and registers the cache metrics collectors.
-// It returns an error if any of the collectors fail to register.
+// NewMetrics creates and registers the cache metrics collectors, reusing those registered by another cache.
+// It panics if any of the collectors fail to register otherwise.
 func NewMetrics() Metrics {
 	m := Metrics{
 		Hits: prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -32,11 +38,39 @@ func NewMetrics() Metrics {
 			Name: "cache_size",
 			Help: "The size of a set within the cache, identified by its key",
 		}, []string{KeyPrefixLabel}),
+		LocalHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "local_cache_hit_count",
+			Help: "The number of in-process cache hits",
+		}, []string{KeyPrefixLabel}),
+		LocalMisses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "local_cache_miss_count",
+			Help: "The number of in-process cache misses",
+		}, []string{KeyPrefixLabel}),
+		LocalSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "local_cache_size",
+			Help: "The number of entries in the in-process cache",
+		}, []string{KeyPrefixLabel}),
 	}
-	prometheus.MustRegister(
-		m.Hits,
-		m.Misses,
-		m.Size,
-	)
+	m.Hits = register(m.Hits)
+	m.Misses = register(m.Misses)
+	m.Size = register(m.Size)
+	m.LocalHits = register(m.LocalHits)
+	m.LocalMisses = register(m.LocalMisses)
+	m.LocalSize = register(m.LocalSize)
 	return m
 }
+
+// register registers a collector, or returns the one registered under the same name before,
+// so several caches in one process, e.g. in tests, share their metrics.
+func register[T prometheus.Collector](c T) T {
+	if err := prometheus.Register(c); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if errors.As(err, &registered) {
+			if existing, ok := registered.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
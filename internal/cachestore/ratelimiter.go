@@ -2,14 +2,21 @@ package cachestore
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
 	"errors"
 	"log/slog"
+	"net"
+	"strconv"
 	"time"
 
 	"github.com/ndajr/urlshortener-go/internal/config"
+	"github.com/ndajr/urlshortener-go/internal/core"
 	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
@@ -50,58 +57,175 @@ const script = `
 	redis.call('HMSET', key, 'tokens', tokens, 'last_refill', last_refill)
 	redis.call('EXPIRE', key, refill_period * 2)
 
-	return allowed and 1 or 0
+	-- Seconds until the next refill
+	local reset = last_refill + refill_period - now
+
+	return {allowed and 1 or 0, tokens, reset}
 `
 
+// Trailer keys describing the caller's bucket, attached to rate limited calls.
+const (
+	TrailerRetryAfter = "retry-after"
+	TrailerLimit      = "x-ratelimit-limit"
+	TrailerRemaining  = "x-ratelimit-remaining"
+)
+
 // RateLimiter implements a Redis-based token bucket rate limiter
 type RateLimiter struct {
 	logger *slog.Logger
 	client *redis.Client
 	config config.RateLimiter
+	// trustedProxies is the number of proxies in front of the service whose x-forwarded-for entries are trusted.
+	trustedProxies int
 }
 
-// NewRateLimiter creates a new rate limiter with the given configuration
-func NewRateLimiter(logger *slog.Logger, cache *Cache, cfg config.RateLimiter) RateLimiter {
+// NewRateLimiter creates a new rate limiter with the given configuration.
+// Unauthenticated callers are told apart by their address, behind trustedProxies proxies.
+func NewRateLimiter(logger *slog.Logger, cache *Cache, cfg config.RateLimiter, trustedProxies int) RateLimiter {
 	return RateLimiter{
-		logger: logger,
-		client: cache.rdb,
-		config: cfg,
+		logger:         logger,
+		client:         cache.rdb,
+		config:         cfg,
+		trustedProxies: trustedProxies,
 	}
 }
 
-// Allow checks if a request is allowed for the given key
-func (rl RateLimiter) Allow(ctx context.Context, key string) (bool, error) {
+// Allowance is the outcome of a rate limit check.
+type Allowance struct {
+	Allowed    bool
+	Limit      int           // Capacity of the bucket
+	Remaining  int           // Tokens left in the bucket
+	RetryAfter time.Duration // Time until the bucket is refilled
+}
+
+// Allow checks if a request is allowed for the given key.
+// Each key has its own bucket, sized by the client overrides if the key has any.
+func (rl RateLimiter) Allow(ctx context.Context, key string) (Allowance, error) {
 	redisKey := rl.config.KeyPrefix + key
 	now := time.Now().Unix()
+	limit := rl.limit(key)
 
 	result, err := rl.client.Eval(ctx, script, []string{redisKey},
-		rl.config.Capacity,
-		rl.config.RefillRate,
+		limit.Capacity,
+		limit.RefillRate,
 		int(rl.config.RefillPeriod.Seconds()),
 		now,
 	).Result()
 
 	if err != nil {
 		rl.logger.Error("redis eval failed", "error", err)
-		return false, ErrRateLimiterInternal
+		return Allowance{}, ErrRateLimiterInternal
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 3 {
+		rl.logger.Error("unexpected rate limiter script result", "result", result)
+		return Allowance{}, ErrRateLimiterInternal
 	}
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	reset, _ := values[2].(int64)
+
+	return Allowance{
+		Allowed:    allowed == 1,
+		Limit:      limit.Capacity,
+		Remaining:  int(remaining),
+		RetryAfter: time.Duration(reset) * time.Second,
+	}, nil
+}
 
-	return result.(int64) == 1, nil
+// limit returns the bucket size and refill rate for a client.
+func (rl RateLimiter) limit(key string) config.RateLimit {
+	limit := config.RateLimit{Capacity: rl.config.Capacity, RefillRate: rl.config.RefillRate}
+	if override, ok := rl.config.Clients[key]; ok {
+		if override.Capacity > 0 {
+			limit.Capacity = override.Capacity
+		}
+		if override.RefillRate > 0 {
+			limit.RefillRate = override.RefillRate
+		}
+	}
+	return limit
 }
 
-// UnaryServerInterceptor returns a gRPC interceptor that applies global rate limiting
+// UnaryServerInterceptor returns a gRPC interceptor that rate limits each client separately
 func (rl RateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		allowed, err := rl.Allow(ctx, "global")
+		allowance, err := rl.Allow(ctx, rl.clientIdentity(ctx))
 		if err != nil {
 			rl.logger.Error("rate limiter internal error", "error", err)
 			return nil, status.Error(codes.Internal, ErrRateLimiterInternal.Error())
 		}
 
-		if !allowed {
+		if !allowance.Allowed {
+			trailer := metadata.Pairs(
+				TrailerLimit, strconv.Itoa(allowance.Limit),
+				TrailerRemaining, strconv.Itoa(allowance.Remaining),
+				// Retry-After has a resolution of seconds, so never tell clients to retry immediately.
+				TrailerRetryAfter, strconv.Itoa(max(1, int(allowance.RetryAfter.Seconds()))),
+			)
+			if err := grpc.SetTrailer(ctx, trailer); err != nil {
+				rl.logger.Warn("failed to set rate limit trailers", "error", err)
+			}
 			return nil, status.Error(codes.ResourceExhausted, ErrRateLimiterExceeded.Error())
 		}
 
 		return handler(ctx, req)
 	}
 }
+
+// clientIdentity returns the key a caller is rate limited by: "key:<name>" of the API key that
+// authenticated the call, or "ip:<address>" of the client otherwise. Only keys the authenticator
+// validated count, so callers cannot get a fresh bucket by sending made-up keys.
+func (rl RateLimiter) clientIdentity(ctx context.Context) string {
+	if principal, ok := core.PrincipalFromContext(ctx); ok {
+		return "key:" + principal.Name
+	}
+	return "ip:" + ClientIP(ctx, rl.trustedProxies)
+}
+
+// gatewayTokenKey is the metadata key the HTTP gateway marks the calls it relays with, see GatewayDialOption.
+const gatewayTokenKey = "x-gateway-token"
+
+// gatewayToken is random per process, so callers connecting on their own, even over loopback from a
+// sidecar, cannot pass as the gateway.
+var gatewayToken = rand.Text()
+
+// GatewayDialOption marks the calls of the gateway's client connection as relayed by the HTTP gateway,
+// so ClientIP trusts the x-forwarded-for entry it appends.
+func GatewayDialOption() grpc.DialOption {
+	return grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, gatewayTokenKey, gatewayToken)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	})
+}
+
+// ClientIP returns the address of the caller: the peer address, or the x-forwarded-for entry added by
+// the outermost of the trustedProxies proxies in front of the service. Calls relayed by the HTTP gateway
+// count it as one more trusted proxy, as it appends the address of the HTTP client.
+func ClientIP(ctx context.Context, trustedProxies int) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if relayedByGateway(md) {
+		trustedProxies++
+	}
+	return core.ClientIP(md.Get("x-forwarded-for"), host, trustedProxies)
+}
+
+// relayedByGateway reports whether a call carries the token of the gateway of this process. Clients can
+// send tokens of their own through the gateway, so any of the values may be the gateway's.
+func relayedByGateway(md metadata.MD) bool {
+	for _, token := range md.Get(gatewayTokenKey) {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(gatewayToken)) == 1 {
+			return true
+		}
+	}
+	return false
+}
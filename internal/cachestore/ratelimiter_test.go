@@ -0,0 +1,255 @@
+package cachestore
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ndajr/urlshortener-go/internal/config"
+	"github.com/ndajr/urlshortener-go/internal/core"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		peer           string
+		gateway        bool
+		forwardedFor   []string
+		trustedProxies int
+		want           string
+	}{
+		{
+			name:         "ClientIP/success_using_peer_without_trusted_proxies",
+			peer:         "203.0.113.5:41000",
+			forwardedFor: []string{"198.51.100.1"},
+			want:         "203.0.113.5",
+		},
+		{
+			name:           "ClientIP/success_using_entry_of_trusted_proxy",
+			peer:           "10.0.0.2:41000",
+			forwardedFor:   []string{"198.51.100.9, 198.51.100.1"},
+			trustedProxies: 1,
+			want:           "198.51.100.1",
+		},
+		{
+			name:         "ClientIP/success_using_entry_of_gateway",
+			peer:         "127.0.0.1:41000",
+			gateway:      true,
+			forwardedFor: []string{"198.51.100.9, 198.51.100.1"},
+			want:         "198.51.100.1",
+		},
+		{
+			name:         "ClientIP/success_ignoring_entries_from_loopback_without_gateway_token",
+			peer:         "127.0.0.1:41000",
+			forwardedFor: []string{"198.51.100.9, 198.51.100.1"},
+			want:         "127.0.0.1",
+		},
+		{
+			name:           "ClientIP/success_using_entry_of_proxy_in_front_of_gateway",
+			peer:           "[::1]:41000",
+			gateway:        true,
+			forwardedFor:   []string{"198.51.100.9", "203.0.113.5, 10.0.0.2"},
+			trustedProxies: 1,
+			want:           "203.0.113.5",
+		},
+		{
+			name:           "ClientIP/success_using_first_entry_behind_fewer_proxies",
+			peer:           "10.0.0.2:41000",
+			forwardedFor:   []string{"198.51.100.1"},
+			trustedProxies: 3,
+			want:           "198.51.100.1",
+		},
+		{
+			name: "ClientIP/success_using_loopback_peer_without_entries",
+			peer: "127.0.0.1:41000",
+			want: "127.0.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := callContext(t, tt.peer, tt.forwardedFor...)
+			if tt.gateway {
+				ctx = relayed(ctx)
+			}
+			require.Equal(t, tt.want, ClientIP(ctx, tt.trustedProxies))
+		})
+	}
+}
+
+func TestClientIdentity(t *testing.T) {
+	rl := RateLimiter{logger: discardLogger}
+
+	tests := []struct {
+		name string
+		ctx  func(t *testing.T) context.Context
+		want string
+	}{
+		{
+			name: "Identity/success_using_api_key_name",
+			ctx: func(t *testing.T) context.Context {
+				return core.WithPrincipal(relayed(callContext(t, "127.0.0.1:41000", "198.51.100.1")), core.Principal{Name: "reporting"})
+			},
+			want: "key:reporting",
+		},
+		{
+			name: "Identity/success_using_client_address",
+			ctx: func(t *testing.T) context.Context {
+				return relayed(callContext(t, "127.0.0.1:41000", "198.51.100.1"))
+			},
+			want: "ip:198.51.100.1",
+		},
+		{
+			name: "Identity/success_ignoring_unvalidated_api_key",
+			ctx: func(t *testing.T) context.Context {
+				ctx := relayed(callContext(t, "127.0.0.1:41000", "198.51.100.1"))
+				md, _ := metadata.FromIncomingContext(ctx)
+				md = metadata.Join(md, metadata.Pairs("x-api-key", "usk_made-up"))
+				return metadata.NewIncomingContext(ctx, md)
+			},
+			want: "ip:198.51.100.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, rl.clientIdentity(tt.ctx(t)))
+		})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	rl := newTestRateLimiter(t, config.RateLimiter{
+		KeyPrefix:    "ratelimit:",
+		Capacity:     2,
+		RefillRate:   1,
+		RefillPeriod: time.Minute,
+		Clients: map[string]config.RateLimit{
+			"key:batch-importer": {Capacity: 5},
+			"ip:10.0.0.7":        {RefillRate: 10},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		assert func(t *testing.T)
+	}{
+		{
+			name: "RateLimiter/success_using_named_client_override",
+			assert: func(t *testing.T) {
+				requireAllowed(t, rl, "key:batch-importer", 5)
+				requireAllowed(t, rl, "key:reporting", 2)
+			},
+		},
+		{
+			name: "RateLimiter/success_keeping_defaults_not_overridden",
+			assert: func(t *testing.T) {
+				require.Equal(t, config.RateLimit{Capacity: 2, RefillRate: 10}, rl.limit("ip:10.0.0.7"))
+				require.Equal(t, config.RateLimit{Capacity: 5, RefillRate: 1}, rl.limit("key:batch-importer"))
+				require.Equal(t, config.RateLimit{Capacity: 2, RefillRate: 1}, rl.limit("ip:10.0.0.8"))
+			},
+		},
+		{
+			name: "RateLimiter/failure_after_rotating_forwarded_for",
+			assert: func(t *testing.T) {
+				interceptor := rl.UnaryServerInterceptor()
+				handler := func(context.Context, any) (any, error) { return "ok", nil }
+				call := func(forwardedFor string) error {
+					_, err := interceptor(relayed(callContext(t, "127.0.0.1:41000", forwardedFor)), nil, &grpc.UnaryServerInfo{}, handler)
+					return err
+				}
+
+				for range 2 {
+					require.NoError(t, call("203.0.113.80"))
+				}
+				// The gateway appends the real client after whatever the client sent, so forged entries do not count.
+				for _, forged := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"} {
+					require.Equal(t, codes.ResourceExhausted, status.Code(call(forged+", 203.0.113.80")))
+				}
+			},
+		},
+		{
+			name: "RateLimiter/failure_after_rotating_forwarded_for_over_loopback",
+			assert: func(t *testing.T) {
+				interceptor := rl.UnaryServerInterceptor()
+				handler := func(context.Context, any) (any, error) { return "ok", nil }
+				call := func(ctx context.Context) error {
+					_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+					return err
+				}
+
+				// A local process calling without the gateway is limited by its own address, whatever it
+				// forwards, or whichever token it makes up.
+				for _, forged := range []string{"198.51.100.11", "198.51.100.12"} {
+					require.NoError(t, call(callContext(t, "127.0.0.2:41000", forged)))
+				}
+				ctx := callContext(t, "127.0.0.2:41000", "198.51.100.13")
+				md, _ := metadata.FromIncomingContext(ctx)
+				md = metadata.Join(md, metadata.Pairs(gatewayTokenKey, "made-up"))
+				require.Equal(t, codes.ResourceExhausted, status.Code(call(metadata.NewIncomingContext(ctx, md))))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assert(t)
+		})
+	}
+}
+
+// newTestRateLimiter returns a rate limiter backed by an in-memory Redis.
+func newTestRateLimiter(t *testing.T, cfg config.RateLimiter) RateLimiter {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return RateLimiter{logger: discardLogger, client: rdb, config: cfg}
+}
+
+// requireAllowed checks that a client gets exactly n calls through before it is limited.
+func requireAllowed(t *testing.T, rl RateLimiter, key string, n int) {
+	t.Helper()
+	for i := range n {
+		allowance, err := rl.Allow(context.Background(), key)
+		require.NoError(t, err)
+		require.True(t, allowance.Allowed, "call %d of %s", i+1, key)
+		require.Equal(t, n, allowance.Limit)
+	}
+	allowance, err := rl.Allow(context.Background(), key)
+	require.NoError(t, err)
+	require.False(t, allowance.Allowed, key)
+	require.Zero(t, allowance.Remaining)
+}
+
+// relayed marks a call context as relayed by the HTTP gateway, as GatewayDialOption does.
+func relayed(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return metadata.NewIncomingContext(ctx, metadata.Join(md, metadata.Pairs(gatewayTokenKey, gatewayToken)))
+}
+
+// callContext returns the context of a gRPC call from peerAddr, carrying the given x-forwarded-for values.
+func callContext(t *testing.T, peerAddr string, forwardedFor ...string) context.Context {
+	t.Helper()
+	addr, err := net.ResolveTCPAddr("tcp", peerAddr)
+	require.NoError(t, err)
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	md := metadata.MD{}
+	for _, value := range forwardedFor {
+		md.Append("x-forwarded-for", value)
+	}
+	return metadata.NewIncomingContext(ctx, md)
+}
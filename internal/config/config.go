@@ -1,6 +1,11 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hypedn/mflag"
@@ -10,6 +15,22 @@ const (
 	appGrpcEndpoint = "grpc_endpoint"
 	appHttpEndpoint = "http_endpoint"
 	appDBAddress    = "db_address"
+	appDBDriver     = "db_driver"
+	appGeoIPDB      = "geoip_database"
+	appProxies      = "trusted_proxies"
+	appPublicURL    = "public_url"
+)
+
+const (
+	shortenerKey          = "shortener"
+	shortenerDeduplicate  = "deduplicate"
+	shortenerMaxBatchSize = "max_batch_size"
+	shortenerPwdAttempts  = "password_max_attempts"
+	shortenerPwdLockout   = "password_lockout"
+	shortenerPwdFailures  = "password_max_link_failures"
+	shortenerPolicyFile   = "destination_policy"
+	shortenerPolicyReload = "destination_policy_reload_interval"
+	shortenerResolveHosts = "resolve_destinations"
 )
 
 const (
@@ -18,6 +39,8 @@ const (
 	redisPoolSize  = "pool_size"
 	redisUrlTTL    = "url_ttl"
 	redisUrlPrefix = "url_prefix"
+	redisLocalSize = "local_cache_size"
+	redisLocalTTL  = "local_cache_ttl"
 )
 
 const (
@@ -26,12 +49,48 @@ const (
 	rateLimiterCapacity     = "capacity"
 	rateLimiterRefillRate   = "refill_rate"
 	rateLimiterRefillPeriod = "refill_period"
+	rateLimiterClientCap    = "client_capacity"
+	rateLimiterClientRefill = "client_refill_rate"
+)
+
+const (
+	authKey          = "auth"
+	authEnabled      = "auth_enabled"
+	authBootstrapKey = "bootstrap_api_key"
+	authMaxFailures  = "auth_max_failures"
+	authFailWindow   = "auth_failure_window"
+)
+
+const (
+	analyticsKey           = "analytics"
+	analyticsBufferSize    = "click_buffer_size"
+	analyticsBatchSize     = "click_batch_size"
+	analyticsFlushInterval = "click_flush_interval"
 )
 
 type AppSettings struct {
 	GrpcEndpoint string
 	HttpEndpoint string
 	DBAddress    string
+	DBDriver     string // "postgres" or "memory"
+	GeoIPDB      string // Path to a MaxMind-format country database, e.g. GeoLite2-Country.mmdb. Optional
+	// TrustedProxies is the number of proxies in front of the service, e.g. a load balancer, whose
+	// X-Forwarded-For entries are trusted for the client address. 0 uses the peer address.
+	TrustedProxies int
+	// PublicURL is the base URL short links are served on, e.g. https://sho.rt, used wherever the
+	// service hands out short URLs rather than trusting the Host header of a request.
+	PublicURL string
+}
+
+type Shortener struct {
+	Deduplicate         bool          // Reuse the existing short code when the same URL is shortened again
+	MaxBatchSize        int           // Maximum number of URLs accepted by a single BatchShortenURL call
+	PasswordMaxAttempts int           // Password attempts allowed per protected link and client within PasswordLockout
+	PasswordLockout     time.Duration // How long attempts are counted for, from a client's first attempt on a link
+	PasswordMaxFailures int           // Incorrect passwords allowed per protected link within PasswordLockout, from all clients together
+	PolicyFile          string        // Path to a YAML policy of the destinations links may point to. Optional
+	PolicyReload        time.Duration // How often the policy file is checked for changes
+	ResolveDestinations bool          // Reject destinations whose host name resolves to an internal address
 }
 
 type Redis struct {
@@ -39,54 +98,233 @@ type Redis struct {
 	UrlPrefix string
 	PoolSize  int
 	UrlTTL    time.Duration
+	LocalSize int           // Maximum URLs kept in the in-process cache in front of Redis, 0 disables it
+	LocalTTL  time.Duration // How long an URL may be served from the in-process cache before rechecking Redis
 }
 
 type RateLimiter struct {
-	KeyPrefix    string        // Redis key prefix
-	Capacity     int           // Maximum tokens in bucket
-	RefillRate   int           // Tokens added per period
-	RefillPeriod time.Duration // How often to refill tokens
+	KeyPrefix    string               // Redis key prefix
+	Capacity     int                  // Maximum tokens in each client's bucket
+	RefillRate   int                  // Tokens added per period
+	RefillPeriod time.Duration        // How often to refill tokens
+	Clients      map[string]RateLimit // Overrides of Capacity and RefillRate, by client identity
+}
+
+type RateLimit struct {
+	Capacity   int
+	RefillRate int
+}
+
+type Auth struct {
+	Enabled       bool          // Require an API key on every API call. Redirects stay public
+	BootstrapKey  string        // Admin API key accepted without being stored, to create the first keys
+	MaxFailures   int           // Invalid API keys a client may send within FailureWindow, before its calls are turned away unchecked
+	FailureWindow time.Duration // How long invalid keys are counted for, from a client's first one
+}
+
+type Analytics struct {
+	BufferSize    int           // Maximum clicks queued in memory before new ones are dropped
+	BatchSize     int           // Maximum clicks written to the database at once
+	FlushInterval time.Duration // How often queued clicks are written, even if the batch is not full
 }
 
 func SetDefaults() {
 	mflag.SetDefault(appHttpEndpoint, "localhost:8080")
 	mflag.SetDefault(appGrpcEndpoint, "localhost:8081")
 	mflag.SetDefault(appDBAddress, "postgres://ndev:@localhost:5432/urlshortener?sslmode=disable")
+	mflag.SetDefault(appDBDriver, "postgres")
+	mflag.SetDefault(appGeoIPDB, "")
+	mflag.SetDefault(appProxies, 0)
+	mflag.SetDefault(appPublicURL, "http://localhost:8080")
 
+	mflag.SetDefault(shortenerKey, map[string]interface{}{
+		shortenerDeduplicate:  false,
+		shortenerMaxBatchSize: 500,
+		shortenerPwdAttempts:  5,
+		shortenerPwdLockout:   15 * time.Minute,
+		shortenerPwdFailures:  100,
+		shortenerPolicyFile:   "",
+		shortenerPolicyReload: 30 * time.Second,
+		shortenerResolveHosts: true,
+	})
 	mflag.SetDefault(redisKey, map[string]interface{}{
 		redisAddr:      "localhost:6379",
 		redisPoolSize:  10,
 		redisUrlTTL:    time.Hour,
 		redisUrlPrefix: "url",
+		redisLocalSize: 1000,
+		redisLocalTTL:  10 * time.Second,
 	})
 	mflag.SetDefault(rateLimiterKey, map[string]interface{}{
-		rateLimiterKeyPrefix:    "ratelimit:", // global rate limiter key
+		rateLimiterKeyPrefix:    "ratelimit:", // per client rate limiter key prefix
 		rateLimiterCapacity:     10,           // 10 token burst
 		rateLimiterRefillRate:   40,           // 40 tokens per period
 		rateLimiterRefillPeriod: time.Second,  // Every second
+		rateLimiterClientCap:    []string{},   // e.g. ["ip=10.0.0.7:100", "key=reporting:50"]
+		rateLimiterClientRefill: []string{},   // e.g. ["ip=10.0.0.7:400"]
+	})
+	mflag.SetDefault(authKey, map[string]interface{}{
+		authEnabled:      false,
+		authBootstrapKey: "",
+		authMaxFailures:  20,
+		authFailWindow:   10 * time.Minute,
+	})
+	mflag.SetDefault(analyticsKey, map[string]interface{}{
+		analyticsBufferSize:    10000,
+		analyticsBatchSize:     500,
+		analyticsFlushInterval: 5 * time.Second,
 	})
 }
 
+// GetSettings reads the settings loaded by mflag, and returns an error if any of them is invalid.
 func GetSettings() (
-	AppSettings,
-	Redis,
-	RateLimiter,
+	app AppSettings,
+	shortener Shortener,
+	redis Redis,
+	rateLimiter RateLimiter,
+	auth Auth,
+	analytics Analytics,
+	err error,
 ) {
-	return AppSettings{
-			GrpcEndpoint: mflag.GetString(appGrpcEndpoint),
-			HttpEndpoint: mflag.GetString(appHttpEndpoint),
-			DBAddress:    mflag.GetString(appDBAddress),
-		},
-		Redis{
-			Addr:      mflag.GetString(redisAddr),
-			PoolSize:  mflag.GetInt(redisPoolSize),
-			UrlTTL:    mflag.GetDuration(redisUrlTTL),
-			UrlPrefix: mflag.GetString(redisUrlPrefix),
-		},
-		RateLimiter{
-			KeyPrefix:    mflag.GetString(rateLimiterKeyPrefix),
-			Capacity:     mflag.GetInt(rateLimiterCapacity),
-			RefillRate:   mflag.GetInt(rateLimiterRefillRate),
-			RefillPeriod: mflag.GetDuration(rateLimiterRefillPeriod),
+	app = AppSettings{
+		GrpcEndpoint:   mflag.GetString(appGrpcEndpoint),
+		HttpEndpoint:   mflag.GetString(appHttpEndpoint),
+		DBAddress:      mflag.GetString(appDBAddress),
+		DBDriver:       mflag.GetString(appDBDriver),
+		GeoIPDB:        mflag.GetString(appGeoIPDB),
+		TrustedProxies: mflag.GetInt(appProxies),
+		PublicURL:      strings.TrimSuffix(mflag.GetString(appPublicURL), "/"),
+	}
+	shortener = Shortener{
+		Deduplicate:         mflag.GetBool(shortenerDeduplicate),
+		MaxBatchSize:        mflag.GetInt(nested(shortenerKey, shortenerMaxBatchSize)),
+		PasswordMaxAttempts: mflag.GetInt(nested(shortenerKey, shortenerPwdAttempts)),
+		PasswordLockout:     mflag.GetDuration(nested(shortenerKey, shortenerPwdLockout)),
+		PasswordMaxFailures: mflag.GetInt(nested(shortenerKey, shortenerPwdFailures)),
+		PolicyFile:          mflag.GetString(nested(shortenerKey, shortenerPolicyFile)),
+		PolicyReload:        mflag.GetDuration(nested(shortenerKey, shortenerPolicyReload)),
+		ResolveDestinations: mflag.GetBool(nested(shortenerKey, shortenerResolveHosts)),
+	}
+	redis = Redis{
+		Addr:      mflag.GetString(nested(redisKey, redisAddr)),
+		PoolSize:  mflag.GetInt(nested(redisKey, redisPoolSize)),
+		UrlTTL:    mflag.GetDuration(nested(redisKey, redisUrlTTL)),
+		UrlPrefix: mflag.GetString(nested(redisKey, redisUrlPrefix)),
+		LocalSize: mflag.GetInt(nested(redisKey, redisLocalSize)),
+		LocalTTL:  mflag.GetDuration(nested(redisKey, redisLocalTTL)),
+	}
+	clients, clientsErr := rateLimiterClients(
+		mflag.GetStringSlice(nested(rateLimiterKey, rateLimiterClientCap)),
+		mflag.GetStringSlice(nested(rateLimiterKey, rateLimiterClientRefill)),
+	)
+	rateLimiter = RateLimiter{
+		KeyPrefix:    mflag.GetString(nested(rateLimiterKey, rateLimiterKeyPrefix)),
+		Capacity:     mflag.GetInt(nested(rateLimiterKey, rateLimiterCapacity)),
+		RefillRate:   mflag.GetInt(nested(rateLimiterKey, rateLimiterRefillRate)),
+		RefillPeriod: mflag.GetDuration(nested(rateLimiterKey, rateLimiterRefillPeriod)),
+		Clients:      clients,
+	}
+	auth = Auth{
+		Enabled:       mflag.GetBool(authEnabled),
+		BootstrapKey:  mflag.GetString(authBootstrapKey),
+		MaxFailures:   mflag.GetInt(authMaxFailures),
+		FailureWindow: mflag.GetDuration(authFailWindow),
+	}
+	analytics = Analytics{
+		BufferSize:    mflag.GetInt(nested(analyticsKey, analyticsBufferSize)),
+		BatchSize:     mflag.GetInt(nested(analyticsKey, analyticsBatchSize)),
+		FlushInterval: mflag.GetDuration(nested(analyticsKey, analyticsFlushInterval)),
+	}
+	return app, shortener, redis, rateLimiter, auth, analytics, errors.Join(
+		app.validate(),
+		shortener.validate(),
+		rateLimiter.validate(),
+		clientsErr,
+	)
+}
+
+// validate rejects a public URL that short URLs cannot be built on.
+func (a AppSettings) validate() error {
+	u, err := url.Parse(a.PublicURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("config: %s must be an http or https URL without query, got %q", appPublicURL, a.PublicURL)
+	}
+	return nil
+}
+
+// validate rejects shortener settings that would turn every call away, e.g. a batch size of 0, or
+// password limits of 0, which would lock every protected link even for its password.
+func (s Shortener) validate() error {
+	switch {
+	case s.MaxBatchSize <= 0:
+		return fmt.Errorf("config: %s must be positive, got %d", nested(shortenerKey, shortenerMaxBatchSize), s.MaxBatchSize)
+	case s.PasswordMaxAttempts <= 0:
+		return fmt.Errorf("config: %s must be positive, got %d", nested(shortenerKey, shortenerPwdAttempts), s.PasswordMaxAttempts)
+	case s.PasswordMaxFailures <= 0:
+		return fmt.Errorf("config: %s must be positive, got %d", nested(shortenerKey, shortenerPwdFailures), s.PasswordMaxFailures)
+	case s.PasswordLockout <= 0:
+		return fmt.Errorf("config: %s must be positive, got %s", nested(shortenerKey, shortenerPwdLockout), s.PasswordLockout)
+	}
+	return nil
+}
+
+// validate rejects rate limits that would turn every call away.
+func (r RateLimiter) validate() error {
+	switch {
+	case r.Capacity <= 0:
+		return fmt.Errorf("config: %s must be positive, got %d", nested(rateLimiterKey, rateLimiterCapacity), r.Capacity)
+	case r.RefillRate <= 0:
+		return fmt.Errorf("config: %s must be positive, got %d", nested(rateLimiterKey, rateLimiterRefillRate), r.RefillRate)
+	case r.RefillPeriod <= 0:
+		return fmt.Errorf("config: %s must be positive, got %s", nested(rateLimiterKey, rateLimiterRefillPeriod), r.RefillPeriod)
+	}
+	return nil
+}
+
+// nested returns the key of a setting under a section default, e.g. "analytics.click_batch_size",
+// as mflag stores the settings of a section as a nested map.
+func nested(section, key string) string {
+	return section + "." + key
+}
+
+// rateLimiterClients merges the per client capacity and refill rate overrides, given as lists of
+// "<kind>=<id>:<limit>" entries, e.g. "ip=10.0.0.7:100" or "key=reporting:400". Client identities are not
+// used as setting keys, as mflag would split the dots of addresses into nested settings.
+// A client overriding only one of them keeps the default for the other.
+func rateLimiterClients(capacities, refillRates []string) (map[string]RateLimit, error) {
+	clients := make(map[string]RateLimit)
+	for _, entry := range capacities {
+		client, n, err := parseClientLimit(entry)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %w", nested(rateLimiterKey, rateLimiterClientCap), err)
+		}
+		limit := clients[client]
+		limit.Capacity = n
+		clients[client] = limit
+	}
+	for _, entry := range refillRates {
+		client, n, err := parseClientLimit(entry)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %w", nested(rateLimiterKey, rateLimiterClientRefill), err)
 		}
+		limit := clients[client]
+		limit.RefillRate = n
+		clients[client] = limit
+	}
+	return clients, nil
+}
+
+// parseClientLimit parses a "<kind>=<id>:<limit>" entry into the client identity the rate limiter goes by,
+// e.g. "ip:10.0.0.7", and its limit. The limit follows the last colon, so IPv6 addresses need no brackets.
+func parseClientLimit(entry string) (string, int, error) {
+	kind, rest, ok := strings.Cut(strings.TrimSpace(entry), "=")
+	i := strings.LastIndex(rest, ":")
+	if !ok || (kind != "ip" && kind != "key") || i <= 0 {
+		return "", 0, fmt.Errorf("invalid client limit %q, want ip=<address>:<limit> or key=<name>:<limit>", entry)
+	}
+	n, err := strconv.Atoi(rest[i+1:])
+	if err != nil || n <= 0 {
+		return "", 0, fmt.Errorf("invalid client limit %q, the limit must be a positive number", entry)
+	}
+	return kind + ":" + rest[:i], n, nil
 }
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hypedn/mflag"
+	"github.com/ndajr/urlshortener-go/internal/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain loads the defaults the way the server does, without a config file or flags of its own.
+func TestMain(m *testing.M) {
+	SetDefaults()
+	mflag.Parse()
+	os.Exit(m.Run())
+}
+
+func TestGetSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		assert func(t *testing.T)
+	}{
+		{
+			name: "Defaults/success_reading_analytics",
+			assert: func(t *testing.T) {
+				_, _, _, _, _, analytics, err := GetSettings()
+				require.NoError(t, err)
+				require.Equal(t, Analytics{BufferSize: 10000, BatchSize: 500, FlushInterval: 5 * time.Second}, analytics)
+			},
+		},
+		{
+			name: "Defaults/success_reading_max_batch_size",
+			assert: func(t *testing.T) {
+				_, shortener, _, _, _, _, err := GetSettings()
+				require.NoError(t, err)
+				require.Equal(t, 500, shortener.MaxBatchSize)
+			},
+		},
+		{
+			name: "Defaults/success_reading_password_limits",
+			assert: func(t *testing.T) {
+				_, shortener, _, _, _, _, err := GetSettings()
+				require.NoError(t, err)
+				require.Equal(t, 5, shortener.PasswordMaxAttempts)
+				require.Equal(t, 100, shortener.PasswordMaxFailures)
+				require.Equal(t, 15*time.Minute, shortener.PasswordLockout)
+			},
+		},
+		{
+			name: "Validate/failure_on_non_positive_password_limits",
+			assert: func(t *testing.T) {
+				_, defaults, _, _, _, _, _ := GetSettings()
+				for key, change := range map[string]func(s *Shortener){
+					"shortener.password_max_attempts":      func(s *Shortener) { s.PasswordMaxAttempts = 0 },
+					"shortener.password_max_link_failures": func(s *Shortener) { s.PasswordMaxFailures = -1 },
+					"shortener.password_lockout":           func(s *Shortener) { s.PasswordLockout = 0 },
+				} {
+					shortener := defaults
+					change(&shortener)
+					require.ErrorContains(t, shortener.validate(), key+" must be positive")
+				}
+			},
+		},
+		{
+			name: "Defaults/success_resolving_destinations",
+			assert: func(t *testing.T) {
+				_, shortener, _, _, _, _, err := GetSettings()
+				require.NoError(t, err)
+				require.True(t, shortener.ResolveDestinations)
+			},
+		},
+		{
+			name: "Defaults/success_loading_destination_policy",
+			assert: func(t *testing.T) {
+				_, shortener, _, _, _, _, err := GetSettings()
+				require.NoError(t, err)
+				require.Empty(t, shortener.PolicyFile)
+				require.Equal(t, 30*time.Second, shortener.PolicyReload)
+
+				// Setting only the policy file must be enough to start with a policy.
+				path := filepath.Join(t.TempDir(), "policy.yaml")
+				require.NoError(t, os.WriteFile(path, []byte("block:\n  - domain: phishing.example.net\n"), 0o600))
+				_, err = policy.Load(slog.New(slog.DiscardHandler), path, shortener.PolicyReload)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Defaults/success_reading_rate_limiter",
+			assert: func(t *testing.T) {
+				_, _, _, rateLimiter, _, _, err := GetSettings()
+				require.NoError(t, err)
+				require.Equal(t, RateLimiter{
+					KeyPrefix:    "ratelimit:",
+					Capacity:     10,
+					RefillRate:   40,
+					RefillPeriod: time.Second,
+					Clients:      map[string]RateLimit{},
+				}, rateLimiter)
+			},
+		},
+		{
+			name: "Defaults/success_reading_redis_with_local_tier",
+			assert: func(t *testing.T) {
+				_, _, redis, _, _, _, err := GetSettings()
+				require.NoError(t, err)
+				require.Equal(t, Redis{
+					Addr:      "localhost:6379",
+					UrlPrefix: "url",
+					PoolSize:  10,
+					UrlTTL:    time.Hour,
+					LocalSize: 1000,
+					LocalTTL:  10 * time.Second,
+				}, redis)
+			},
+		},
+		{
+			name: "Defaults/success_reading_public_url",
+			assert: func(t *testing.T) {
+				app, _, _, _, _, _, err := GetSettings()
+				require.NoError(t, err)
+				require.Equal(t, "http://localhost:8080", app.PublicURL)
+			},
+		},
+		{
+			name: "Validate/failure_on_invalid_public_url",
+			assert: func(t *testing.T) {
+				for _, publicURL := range []string{"", "sho.rt", "ftp://sho.rt", "https://", "https://sho.rt/?ref=qr"} {
+					require.ErrorContains(t, AppSettings{PublicURL: publicURL}.validate(), "public_url must be an http or https URL", publicURL)
+				}
+				require.NoError(t, AppSettings{PublicURL: "https://sho.rt/l"}.validate())
+			},
+		},
+		{
+			name: "Validate/failure_on_non_positive_max_batch_size",
+			assert: func(t *testing.T) {
+				_, shortener, _, _, _, _, _ := GetSettings()
+				for _, size := range []int{0, -1} {
+					shortener.MaxBatchSize = size
+					require.ErrorContains(t, shortener.validate(), "shortener.max_batch_size must be positive", size)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assert(t)
+		})
+	}
+}
+
+func TestRateLimiterClients(t *testing.T) {
+	tests := []struct {
+		name        string
+		capacities  []string
+		refillRates []string
+		want        map[string]RateLimit
+		wantErr     string
+	}{
+		{
+			name:        "Clients/success_merging_overrides",
+			capacities:  []string{"ip=10.0.0.7:100", "key=batch-importer:500"},
+			refillRates: []string{" ip=10.0.0.7:400 ", "ip=2001:db8::7:40"},
+			want: map[string]RateLimit{
+				"ip:10.0.0.7":        {Capacity: 100, RefillRate: 400},
+				"key:batch-importer": {Capacity: 500},
+				"ip:2001:db8::7":     {RefillRate: 40},
+			},
+		},
+		{
+			name: "Clients/success_without_overrides",
+			want: map[string]RateLimit{},
+		},
+		{
+			name:       "Clients/failure_on_unknown_kind",
+			capacities: []string{"host=10.0.0.7:100"},
+			wantErr:    "rate_limiter.client_capacity: invalid client limit",
+		},
+		{
+			name:        "Clients/failure_without_limit",
+			refillRates: []string{"ip=10.0.0.7"},
+			wantErr:     "rate_limiter.client_refill_rate: invalid client limit",
+		},
+		{
+			name:       "Clients/failure_on_non_positive_limit",
+			capacities: []string{"key=reporting:0"},
+			wantErr:    "the limit must be a positive number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients, err := rateLimiterClients(tt.capacities, tt.refillRates)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, clients)
+		})
+	}
+}
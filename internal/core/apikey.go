@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Scope is the set of operations an API key is allowed to perform.
+type Scope string
+
+const (
+	// ScopeCreate can shorten URLs, and change or remove the links of its owner.
+	ScopeCreate Scope = "create"
+	// ScopeRead can only look up short links, and list and report on the links of its owner.
+	ScopeRead Scope = "read"
+	// ScopeAdmin can do everything, including managing links and API keys.
+	ScopeAdmin Scope = "admin"
+)
+
+// Allows reports whether the scope grants the operations of required.
+func (s Scope) Allows(required Scope) bool {
+	return s == ScopeAdmin || s == required
+}
+
+const (
+	// apiKeyPrefix makes API keys recognizable, e.g. by secret scanners.
+	apiKeyPrefix = "usk_"
+	// apiKeyIDLength is the length of the public identifier of an API key.
+	apiKeyIDLength = 12
+	// apiKeySecretLength is the length of the random part of an API key, about 238 bits of entropy.
+	apiKeySecretLength = 40
+)
+
+// APIKey is a credential used to call the API. Only a hash of the key itself is stored.
+type APIKey struct {
+	CreatedAt time.Time  `db:"created_at"`
+	ID        string     `db:"id"`
+	Name      string     `db:"name"`
+	Owner     string     `db:"owner"`
+	Scope     Scope      `db:"scope"`
+	KeyHash   string     `db:"key_hash"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+// Revoked reports whether the key can no longer be used.
+func (k APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// GenerateAPIKey creates the identifier and secret value of a new API key.
+func GenerateAPIKey() (id string, key string, err error) {
+	id, err = randomString(apiKeyIDLength)
+	if err != nil {
+		return "", "", fmt.Errorf("generateAPIKey: %w", err)
+	}
+	secret, err := randomString(apiKeySecretLength)
+	if err != nil {
+		return "", "", fmt.Errorf("generateAPIKey: %w", err)
+	}
+	return id, apiKeyPrefix + secret, nil
+}
+
+// HashAPIKey returns the hash an API key is stored and looked up by. API keys are long random
+// strings rather than user chosen passwords, so a fast hash is enough.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	KeyID string
+	Name  string
+	// Owner groups the keys of a team or client, which share the links created with any of them.
+	Owner string
+	Scope Scope
+}
+
+// CanManage reports whether the principal may change, list or report on links of the given owner.
+func (p Principal) CanManage(owner string) bool {
+	return p.Scope == ScopeAdmin || p.Owner == owner
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the caller of the request.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the caller of the request, if it was authenticated.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
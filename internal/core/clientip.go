@@ -0,0 +1,21 @@
+package core
+
+import "strings"
+
+// ClientIP returns the address of the client a request came from, given the X-Forwarded-For values it
+// arrived with, the address of its peer, and how many proxies in front of the service are trusted.
+// Each proxy appends the address it got the request from, so only the entries added by trusted proxies,
+// counted from the right, can be relied on: anything further left was sent by the client, and may be forged.
+// With no trusted proxies, the peer is the client. With fewer entries than trusted proxies, the first one is.
+func ClientIP(forwardedFor []string, peer string, trustedProxies int) string {
+	var hops []string
+	for _, value := range forwardedFor {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	hops = append(hops, peer)
+	return hops[max(0, len(hops)-1-trustedProxies)]
+}
@@ -2,17 +2,151 @@ package core
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
+	"strings"
 	"time"
 )
 
 type URL struct {
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	ShortCode string    `db:"short_code" json:"short_code"`
-	LongURL   string    `db:"long_url" json:"long_url"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	ShortCode  string     `db:"short_code" json:"short_code"`
+	LongURL    string     `db:"long_url" json:"long_url"`
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	DisabledAt *time.Time `db:"disabled_at" json:"disabled_at,omitempty"`
+	// Owner is the owner of the API key the URL was created with. Empty if authentication was disabled.
+	Owner string `db:"owner" json:"owner,omitempty"`
+	// PasswordHash is the bcrypt hash of the password required to follow the link. Empty if the link is public.
+	// It is never encoded, so it cannot end up in the cache.
+	PasswordHash string `db:"password_hash" json:"-"`
+	// RedirectStatus is the HTTP status the URL redirects with: 301, 302, 307 or 308.
+	RedirectStatus int `db:"redirect_status" json:"redirect_status,omitempty"`
+	// QueryMode is how the query parameters of a visit are passed on to the long URL.
+	QueryMode QueryMode `db:"query_mode" json:"query_mode,omitempty"`
+	// Targeting sends the visits matching a rule to its destination instead of the long URL.
+	// The first matching rule wins.
+	Targeting []TargetingRule `db:"targeting" json:"targeting,omitempty"`
+	// Variants split the visits no targeting rule matches between several destinations, instead of the long URL.
+	Variants []Variant `db:"variants" json:"variants,omitempty"`
+	// StickyVariants keeps sending returning visitors to the same variant.
+	StickyVariants bool `db:"sticky_variants" json:"sticky_variants,omitempty"`
+	// MaxClicks is the number of redirects after which the URL is used up. Zero if it is unlimited.
+	MaxClicks int `db:"max_clicks" json:"max_clicks,omitempty"`
+	// RemainingClicks is the number of redirects the URL has left, as last read from the database.
+	// Nil if it is unlimited. It is never encoded, as a cached count would be stale.
+	RemainingClicks *int `db:"remaining_clicks" json:"-"`
+	// NotBefore is when the URL goes live. Until then, visits get the placeholder URL or a "not yet available" page.
+	NotBefore *time.Time `db:"not_before" json:"not_before,omitempty"`
+	// PlaceholderURL is where visits are redirected to before NotBefore. Empty for the "not yet available" page.
+	PlaceholderURL string `db:"placeholder_url" json:"placeholder_url,omitempty"`
 }
 
+// Expired reports whether the URL has an expiry that is not after now.
+func (u URL) Expired(now time.Time) bool {
+	return u.ExpiresAt != nil && !now.Before(*u.ExpiresAt)
+}
+
+// Pending reports whether the URL has a start time that is after now.
+func (u URL) Pending(now time.Time) bool {
+	return u.NotBefore != nil && now.Before(*u.NotBefore)
+}
+
+// Disabled reports whether the URL has been turned off.
+func (u URL) Disabled() bool {
+	return u.DisabledAt != nil
+}
+
+// ClickLimited reports whether the URL is used up after a number of redirects.
+func (u URL) ClickLimited() bool {
+	return u.MaxClicks > 0
+}
+
+// UsedUp reports whether the URL has no redirects left.
+func (u URL) UsedUp() bool {
+	return u.RemainingClicks != nil && *u.RemainingClicks <= 0
+}
+
+// Protected reports whether following the URL requires a password.
+func (u URL) Protected() bool {
+	return u.PasswordHash != ""
+}
+
+// Redirect returns the HTTP status to redirect to the URL with. URLs cached before
+// the status was stored redirect with 302 Found.
+func (u URL) Redirect() int {
+	if u.RedirectStatus == 0 {
+		return http.StatusFound
+	}
+	return u.RedirectStatus
+}
+
+// PerVisitor reports whether the URL may redirect visitors to different destinations.
+func (u URL) PerVisitor() bool {
+	return len(u.Targeting) > 0 || len(u.Variants) > 0
+}
+
+// PermanentRedirect reports whether the URL redirects with a permanent status, which clients may cache.
+func (u URL) PermanentRedirect() bool {
+	return u.Redirect() == http.StatusMovedPermanently || u.Redirect() == http.StatusPermanentRedirect
+}
+
+// URLFilter selects a page of URLs, ordered from newest to oldest.
+type URLFilter struct {
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Contains matches URLs whose long URL contains it, ignoring case.
+	Contains string
+	// Owner matches URLs created by the given owner, if set.
+	Owner string
+	// After continues the listing right after the given position, if set.
+	After *URLCursor
+	Limit int
+}
+
+// URLCursor is a position in a listing of URLs.
+type URLCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ShortCode string    `json:"short_code"`
+}
+
+// Click is a single visit of a short link.
+type Click struct {
+	ClickedAt time.Time
+	ShortCode string
+	Referrer  string
+	UserAgent string
+	ClientIP  string
+	// Variant is the name of the variant the visit was sent to. Empty if the link has none.
+	Variant string
+}
+
+// LinkStats aggregates the clicks of a short link.
+type LinkStats struct {
+	TotalClicks int64
+	Daily       []DailyClicks
+	// Variants are the clicks on each variant of a split link, ordered by name.
+	Variants []VariantClicks
+}
+
+// DailyClicks is the number of clicks on a single UTC day.
+type DailyClicks struct {
+	Day    time.Time `db:"day"`
+	Clicks int64     `db:"clicks"`
+}
+
+// VariantClicks is the number of clicks sent to a variant of a split link.
+type VariantClicks struct {
+	Variant string `db:"variant"`
+	Clicks  int64  `db:"clicks"`
+}
+
+// MaxClicks is the largest click limit of a URL.
+const MaxClicks = 1_000_000
+
+var ErrMaxClicks = fmt.Errorf("max clicks must be between 0 and %d", MaxClicks)
+
 // MaxURLLenght is the maximum allowed length used by Shorten operation.
 const MaxURLLength = 2083
 
@@ -23,15 +157,81 @@ const (
 	shortCodeLength = 6
 )
 
+const (
+	// MinAliasLength is the minimum length of a custom alias.
+	MinAliasLength = 3
+	// MaxAliasLength is the maximum length of a custom alias.
+	MaxAliasLength = 32
+	// aliasChars are the characters allowed in a custom alias.
+	aliasChars = base62Chars + "-_"
+)
+
+// redirectStatuses are the HTTP statuses a URL may redirect with.
+var redirectStatuses = map[int]struct{}{
+	http.StatusMovedPermanently:  {},
+	http.StatusFound:             {},
+	http.StatusTemporaryRedirect: {},
+	http.StatusPermanentRedirect: {},
+}
+
+var ErrRedirectStatus = errors.New("redirect status must be 301, 302, 307 or 308")
+
+var (
+	ErrAliasLength   = fmt.Errorf("custom alias must be between %d and %d characters", MinAliasLength, MaxAliasLength)
+	ErrAliasChars    = errors.New("custom alias may only contain letters, digits, '-' and '_'")
+	ErrAliasReserved = errors.New("custom alias is reserved")
+)
+
+// reservedAliases are path segments already routed by the HTTP server,
+// which would make a short link with the same code unreachable.
+var reservedAliases = map[string]struct{}{
+	"api":     {},
+	"docs":    {},
+	"healthz": {},
+}
+
 // GenerateShortCode creates a random, URL-friendly string.
 func GenerateShortCode() (string, error) {
-	result := make([]byte, shortCodeLength)
+	code, err := randomString(shortCodeLength)
+	if err != nil {
+		return "", fmt.Errorf("generateShortCode: %w", err)
+	}
+	return code, nil
+}
+
+// randomString returns a cryptographically random base62 string of the given length.
+func randomString(length int) (string, error) {
+	result := make([]byte, length)
 	for i := range result {
 		num, err := rand.Int(rand.Reader, big.NewInt(int64(len(base62Chars))))
 		if err != nil {
-			return "", fmt.Errorf("generateShortCode: %w", err)
+			return "", err
 		}
 		result[i] = base62Chars[num.Int64()]
 	}
 	return string(result), nil
 }
+
+// ValidateAlias checks that a user-provided alias can be used as a short code.
+func ValidateAlias(alias string) error {
+	if len(alias) < MinAliasLength || len(alias) > MaxAliasLength {
+		return ErrAliasLength
+	}
+	for _, r := range alias {
+		if !strings.ContainsRune(aliasChars, r) {
+			return ErrAliasChars
+		}
+	}
+	if _, ok := reservedAliases[strings.ToLower(alias)]; ok {
+		return ErrAliasReserved
+	}
+	return nil
+}
+
+// ValidateRedirectStatus checks that a URL may redirect with the given HTTP status.
+func ValidateRedirectStatus(code int) error {
+	if _, ok := redirectStatuses[code]; !ok {
+		return ErrRedirectStatus
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MaxPasswordLength is the maximum length in bytes of a link password, as bcrypt ignores anything longer.
+const MaxPasswordLength = 72
+
+var ErrPasswordLength = fmt.Errorf("password must be at most %d bytes", MaxPasswordLength)
+
+// HashPassword returns the bcrypt hash of a link password.
+func HashPassword(password string) (string, error) {
+	if len(password) > MaxPasswordLength {
+		return "", ErrPasswordLength
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hashPassword: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches a hash returned by HashPassword.
+func CheckPassword(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("checkPassword: %w", err)
+	}
+	return true, nil
+}
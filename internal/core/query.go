@@ -0,0 +1,60 @@
+package core
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// QueryMode is how the query parameters of a visit are passed on to the long URL.
+type QueryMode string
+
+const (
+	// QueryModeOff drops the query parameters of the visit.
+	QueryModeOff QueryMode = ""
+	// QueryModeKeep adds the parameters the long URL does not have, keeping its own values.
+	QueryModeKeep QueryMode = "keep"
+	// QueryModeOverride adds every parameter, replacing the long URL's own values.
+	QueryModeOverride QueryMode = "override"
+	// QueryModeAppend adds every parameter, after the long URL's own values.
+	QueryModeAppend QueryMode = "append"
+)
+
+// passQuery adds the query parameters of a visit to a destination URL, as the mode asks. The destination's
+// own query is kept as it is, apart from the parameters the visit overrides.
+func passQuery(destination string, mode QueryMode, visit url.Values) string {
+	if mode == QueryModeOff || len(visit) == 0 {
+		return destination
+	}
+	dest, err := url.Parse(destination)
+	if err != nil {
+		return destination
+	}
+
+	var pairs []string
+	if dest.RawQuery != "" {
+		pairs = strings.Split(dest.RawQuery, "&")
+	}
+	added := visit
+	switch mode {
+	case QueryModeKeep:
+		own := dest.Query()
+		added = url.Values{}
+		for key, values := range visit {
+			if !own.Has(key) {
+				added[key] = values
+			}
+		}
+	case QueryModeOverride:
+		pairs = slices.DeleteFunc(pairs, func(pair string) bool {
+			key, _, _ := strings.Cut(pair, "=")
+			key, err := url.QueryUnescape(key)
+			return err == nil && visit.Has(key)
+		})
+	}
+	if query := added.Encode(); query != "" {
+		pairs = append(pairs, query)
+	}
+	dest.RawQuery = strings.Join(pairs, "&")
+	return dest.String()
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Operating systems a targeting rule can match.
+const (
+	OSAndroid  = "android"
+	OSChromeOS = "chromeos"
+	OSIOS      = "ios"
+	OSLinux    = "linux"
+	OSMacOS    = "macos"
+	OSWindows  = "windows"
+)
+
+// Device classes a targeting rule can match.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+)
+
+// MaxTargetingRules is the maximum number of targeting rules of a URL.
+const MaxTargetingRules = 20
+
+var (
+	knownOS      = []string{OSAndroid, OSChromeOS, OSIOS, OSLinux, OSMacOS, OSWindows}
+	knownDevices = []string{DeviceDesktop, DeviceMobile, DeviceTablet}
+)
+
+var (
+	ErrTargetingRules     = fmt.Errorf("a link may have at most %d targeting rules", MaxTargetingRules)
+	ErrTargetingCondition = errors.New("targeting rule must match on at least one of os, devices, languages or countries")
+	ErrTargetingOS        = fmt.Errorf("targeting os must be one of %s", strings.Join(knownOS, ", "))
+	ErrTargetingDevice    = fmt.Errorf("targeting device must be one of %s", strings.Join(knownDevices, ", "))
+	ErrTargetingLanguage  = errors.New("targeting language must be a language tag, e.g. en or pt-BR")
+	ErrTargetingCountry   = errors.New("targeting country must be an ISO 3166-1 alpha-2 code, e.g. US")
+)
+
+// TargetingRule sends the visits it matches to its own destination. A visit matches when it matches
+// every condition the rule sets, and a condition matches when any of its values does.
+type TargetingRule struct {
+	// OS are operating systems, e.g. ios.
+	OS []string `json:"os,omitempty"`
+	// Devices are device classes, e.g. mobile.
+	Devices []string `json:"devices,omitempty"`
+	// Languages are lower case language tags. A tag also matches its more specific tags, so "pt" matches "pt-br".
+	Languages []string `json:"languages,omitempty"`
+	// Countries are upper case ISO 3166-1 alpha-2 codes, e.g. US.
+	Countries   []string `json:"countries,omitempty"`
+	Destination string   `json:"destination"`
+}
+
+// Visit is what is known about a visitor, to pick the destination of a URL.
+type Visit struct {
+	// Query are the query parameters of the visit.
+	Query  url.Values
+	OS     string
+	Device string
+	// Language is the visitor's preferred language tag, in lower case.
+	Language string
+	// Country is the ISO 3166-1 alpha-2 code of the visitor's country, in upper case. Empty if unknown.
+	Country string
+	// Variant is the name of the variant the visitor is sent to, if the URL has variants and no rule matches.
+	Variant string
+}
+
+// Matches reports whether the rule applies to the visit.
+func (r TargetingRule) Matches(v Visit) bool {
+	if len(r.OS) > 0 && !slices.Contains(r.OS, v.OS) {
+		return false
+	}
+	if len(r.Devices) > 0 && !slices.Contains(r.Devices, v.Device) {
+		return false
+	}
+	if len(r.Languages) > 0 && !slices.ContainsFunc(r.Languages, func(lang string) bool {
+		return v.Language == lang || strings.HasPrefix(v.Language, lang+"-")
+	}) {
+		return false
+	}
+	if len(r.Countries) > 0 && !slices.Contains(r.Countries, v.Country) {
+		return false
+	}
+	return true
+}
+
+// TargetsCountries reports whether any targeting rule of the URL matches on countries.
+func (u URL) TargetsCountries() bool {
+	return slices.ContainsFunc(u.Targeting, func(r TargetingRule) bool {
+		return len(r.Countries) > 0
+	})
+}
+
+// Targeted reports whether the visit matches a targeting rule of the URL, which takes precedence over its variants.
+func (u URL) Targeted(v Visit) bool {
+	return slices.ContainsFunc(u.Targeting, func(r TargetingRule) bool {
+		return r.Matches(v)
+	})
+}
+
+// Destination returns the URL a visit redirects to: the destination of the first targeting rule it matches,
+// or else of the visit's variant, or else the long URL, with the query parameters of the visit passed on.
+func (u URL) Destination(v Visit) string {
+	destination := u.LongURL
+	if i := slices.IndexFunc(u.Targeting, func(r TargetingRule) bool { return r.Matches(v) }); i >= 0 {
+		destination = u.Targeting[i].Destination
+	} else if variant, ok := u.VariantNamed(v.Variant); ok {
+		destination = variant.Destination
+	}
+	return passQuery(destination, u.QueryMode, v.Query)
+}
+
+// NormalizeTargetingRule checks the conditions of a targeting rule, and returns it with its language
+// tags in lower case and its countries in upper case. The destination is left to the caller to check.
+func NormalizeTargetingRule(rule TargetingRule) (TargetingRule, error) {
+	if len(rule.OS) == 0 && len(rule.Devices) == 0 && len(rule.Languages) == 0 && len(rule.Countries) == 0 {
+		return TargetingRule{}, ErrTargetingCondition
+	}
+	for _, os := range rule.OS {
+		if !slices.Contains(knownOS, os) {
+			return TargetingRule{}, ErrTargetingOS
+		}
+	}
+	for _, device := range rule.Devices {
+		if !slices.Contains(knownDevices, device) {
+			return TargetingRule{}, ErrTargetingDevice
+		}
+	}
+
+	out := TargetingRule{OS: rule.OS, Devices: rule.Devices, Destination: rule.Destination}
+	for _, lang := range rule.Languages {
+		if !validLanguageTag(lang) {
+			return TargetingRule{}, ErrTargetingLanguage
+		}
+		out.Languages = append(out.Languages, strings.ToLower(lang))
+	}
+	for _, country := range rule.Countries {
+		if len(country) != 2 || !isASCIILetters(country) {
+			return TargetingRule{}, ErrTargetingCountry
+		}
+		out.Countries = append(out.Countries, strings.ToUpper(country))
+	}
+	return out, nil
+}
+
+// validLanguageTag reports whether tag looks like a BCP 47 language tag: a 2 or 3 letter
+// language followed by subtags of 1 to 8 letters or digits.
+func validLanguageTag(tag string) bool {
+	subtags := strings.Split(tag, "-")
+	if len(subtags[0]) < 2 || len(subtags[0]) > 3 || !isASCIILetters(subtags[0]) {
+		return false
+	}
+	for _, subtag := range subtags[1:] {
+		if len(subtag) < 1 || len(subtag) > 8 {
+			return false
+		}
+		for _, r := range subtag {
+			if !strings.ContainsRune(base62Chars, r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isASCIILetters(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MinVariants is the minimum number of variants of a split URL.
+	MinVariants = 2
+	// MaxVariants is the maximum number of variants of a split URL.
+	MaxVariants = 10
+	// MaxVariantWeight is the maximum weight of a variant.
+	MaxVariantWeight = 10000
+	// MaxVariantNameLength is the maximum length of a variant name.
+	MaxVariantNameLength = 32
+)
+
+var (
+	ErrVariantCount  = fmt.Errorf("a split link must have between %d and %d variants", MinVariants, MaxVariants)
+	ErrVariantWeight = fmt.Errorf("variant weight must be between 1 and %d", MaxVariantWeight)
+	ErrVariantName   = fmt.Errorf("variant name must be at most %d letters, digits, '-' or '_'", MaxVariantNameLength)
+	ErrVariantTaken  = errors.New("variant names must be unique")
+)
+
+// Variant is one of the destinations a URL splits its visits between, such as one landing page of an experiment.
+type Variant struct {
+	// Name identifies the variant in the recorded clicks.
+	Name        string `json:"name"`
+	Destination string `json:"destination"`
+	// Weight is the share of visits the variant gets, relative to the weights of the others.
+	Weight int `json:"weight"`
+}
+
+// PickVariant returns the variant n falls on. Uniformly distributed values of n pick the variants
+// in proportion to their weights. It returns the zero Variant if the URL has none.
+func (u URL) PickVariant(n uint64) Variant {
+	var total uint64
+	for _, v := range u.Variants {
+		total += uint64(v.Weight)
+	}
+	if total == 0 {
+		return Variant{}
+	}
+	n %= total
+	for _, v := range u.Variants {
+		if n < uint64(v.Weight) {
+			return v
+		}
+		n -= uint64(v.Weight)
+	}
+	return Variant{}
+}
+
+// VariantNamed returns the variant of the URL with the given name.
+func (u URL) VariantNamed(name string) (Variant, bool) {
+	for _, v := range u.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// NormalizeVariants checks the names and weights of the variants of a URL, and names the unnamed ones
+// after their position: A, B, C and so on. The destinations are left to the caller to check.
+func NormalizeVariants(variants []Variant) ([]Variant, error) {
+	if len(variants) < MinVariants || len(variants) > MaxVariants {
+		return nil, ErrVariantCount
+	}
+	out := make([]Variant, 0, len(variants))
+	names := make(map[string]struct{}, len(variants))
+	for i, v := range variants {
+		if v.Weight < 1 || v.Weight > MaxVariantWeight {
+			return nil, ErrVariantWeight
+		}
+		if v.Name == "" {
+			v.Name = string(rune('A' + i))
+		}
+		if len(v.Name) > MaxVariantNameLength || strings.Trim(v.Name, aliasChars) != "" {
+			return nil, ErrVariantName
+		}
+		if _, ok := names[v.Name]; ok {
+			return nil, ErrVariantTaken
+		}
+		names[v.Name] = struct{}{}
+		out = append(out, v)
+	}
+	return out, nil
+}
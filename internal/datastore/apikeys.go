@@ -0,0 +1,128 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ndajr/urlshortener-go/internal/core"
+)
+
+var (
+	ErrAPIKeyNotFound  = errors.New("api key not found")
+	ErrAPIKeyNameTaken = errors.New("api key name already taken")
+)
+
+const (
+	// activeAPIKeyNameIndex is the unique index on the names of keys that are not revoked.
+	activeAPIKeyNameIndex = "idx_api_keys_active_name"
+	// uniqueViolation is the Postgres error code of a unique constraint violation.
+	uniqueViolation = "23505"
+)
+
+// AddAPIKey stores a new API key. It returns ErrAPIKeyNameTaken if an active key already has the same name.
+func (s Store) AddAPIKey(ctx context.Context, key core.APIKey) (core.APIKey, error) {
+	const queryName = "AddAPIKey"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	rows, err := s.db.Query(ctx, insertAPIKey, key.ID, key.Name, key.Owner, string(key.Scope), key.KeyHash)
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.APIKey{}, fmt.Errorf("store: AddAPIKey: %w", err)
+	}
+
+	out, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[core.APIKey])
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == activeAPIKeyNameIndex {
+			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusConflict).Inc()
+			return core.APIKey{}, fmt.Errorf("store: %w", ErrAPIKeyNameTaken)
+		}
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.APIKey{}, fmt.Errorf("store: AddAPIKey: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	return out, nil
+}
+
+// GetAPIKey retrieves an API key by the hash of its value, including revoked keys.
+// It returns ErrAPIKeyNotFound if no key has the given hash.
+func (s Store) GetAPIKey(ctx context.Context, keyHash string) (core.APIKey, error) {
+	const queryName = "GetAPIKey"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	rows, err := s.db.Query(ctx, getAPIKeyByHash, keyHash)
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.APIKey{}, fmt.Errorf("store: GetAPIKey: %w", err)
+	}
+
+	key, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[core.APIKey])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+			return core.APIKey{}, ErrAPIKeyNotFound
+		}
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.APIKey{}, fmt.Errorf("store: GetAPIKey: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	return key, nil
+}
+
+// ListAPIKeys returns every API key, including revoked ones, oldest first.
+func (s Store) ListAPIKeys(ctx context.Context) ([]core.APIKey, error) {
+	const queryName = "ListAPIKeys"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	rows, err := s.db.Query(ctx, listAPIKeys)
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return nil, fmt.Errorf("store: ListAPIKeys: %w", err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowToStructByName[core.APIKey])
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return nil, fmt.Errorf("store: ListAPIKeys: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	return keys, nil
+}
+
+// RevokeAPIKey marks an API key as revoked. Revoking an already revoked key is a no-op.
+// It returns ErrAPIKeyNotFound if the id does not exist.
+func (s Store) RevokeAPIKey(ctx context.Context, id string) error {
+	const queryName = "RevokeAPIKey"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	tag, err := s.db.Exec(ctx, revokeAPIKey, id)
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return fmt.Errorf("store: RevokeAPIKey: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	if tag.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
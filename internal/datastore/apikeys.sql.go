@@ -0,0 +1,25 @@
+package datastore
+
+const (
+	insertAPIKey = `
+	INSERT INTO api_keys (id, name, owner, scope, key_hash)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING *
+	`
+
+	getAPIKeyByHash = `
+	SELECT * FROM api_keys
+	WHERE key_hash = $1
+	`
+
+	listAPIKeys = `
+	SELECT * FROM api_keys
+	ORDER BY created_at, id
+	`
+
+	revokeAPIKey = `
+	UPDATE api_keys
+	SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP)
+	WHERE id = $1
+	`
+)
@@ -0,0 +1,79 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/ndajr/urlshortener-go/internal/core"
+)
+
+// clickColumns are the columns written by AddClicks, in CopyFrom order.
+var clickColumns = []string{"short_code", "clicked_at", "referrer", "user_agent", "client_ip", "variant"}
+
+// AddClicks writes a batch of clicks in a single COPY round trip.
+func (s Store) AddClicks(ctx context.Context, clicks []core.Click) error {
+	const queryName = "AddClicks"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	_, err := s.db.CopyFrom(ctx, pgx.Identifier{"clicks"}, clickColumns,
+		pgx.CopyFromSlice(len(clicks), func(i int) ([]any, error) {
+			c := clicks[i]
+			return []any{c.ShortCode, c.ClickedAt, c.Referrer, c.UserAgent, c.ClientIP, c.Variant}, nil
+		}),
+	)
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return fmt.Errorf("store: AddClicks: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	return nil
+}
+
+// GetLinkStats returns the total number of clicks for a short code, along with a per-day breakdown
+// of the clicks since the given time and the total per variant.
+func (s Store) GetLinkStats(ctx context.Context, shortCode string, since time.Time) (core.LinkStats, error) {
+	const queryName = "GetLinkStats"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	var stats core.LinkStats
+	if err := s.db.QueryRow(ctx, countClicks, shortCode).Scan(&stats.TotalClicks); err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.LinkStats{}, fmt.Errorf("store: GetLinkStats: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx, getDailyClicks, shortCode, since)
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.LinkStats{}, fmt.Errorf("store: GetLinkStats: %w", err)
+	}
+
+	stats.Daily, err = pgx.CollectRows(rows, pgx.RowToStructByName[core.DailyClicks])
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.LinkStats{}, fmt.Errorf("store: GetLinkStats: %w", err)
+	}
+
+	rows, err = s.db.Query(ctx, getVariantClicks, shortCode)
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.LinkStats{}, fmt.Errorf("store: GetLinkStats: %w", err)
+	}
+
+	stats.Variants, err = pgx.CollectRows(rows, pgx.RowToStructByName[core.VariantClicks])
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.LinkStats{}, fmt.Errorf("store: GetLinkStats: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	return stats, nil
+}
@@ -0,0 +1,29 @@
+package datastore
+
+const (
+	countClicks = `
+	SELECT COUNT(*) FROM clicks
+	WHERE short_code = $1
+	`
+
+	getDailyClicks = `
+	SELECT date_trunc('day', clicked_at AT TIME ZONE 'UTC') AS day, COUNT(*) AS clicks
+	FROM clicks
+	WHERE short_code = $1 AND clicked_at >= $2
+	GROUP BY day
+	ORDER BY day
+	`
+
+	getVariantClicks = `
+	SELECT variant, COUNT(*) AS clicks
+	FROM clicks
+	WHERE short_code = $1 AND variant <> ''
+	GROUP BY variant
+	ORDER BY variant
+	`
+
+	deleteClicks = `
+	DELETE FROM clicks
+	WHERE short_code = $1
+	`
+)
@@ -0,0 +1,365 @@
+package datastore
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+)
+
+// MemoryStore is a thread-safe, in-memory Storage. It mirrors the behaviour of the
+// Postgres Store, so the service layer can run without a database.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	urls    map[string]core.URL
+	clicks  map[string][]core.Click
+	apiKeys map[string]core.APIKey
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		urls:    make(map[string]core.URL),
+		clicks:  make(map[string][]core.Click),
+		apiKeys: make(map[string]core.APIKey),
+	}
+}
+
+func (m *MemoryStore) Ping(context.Context) error {
+	return nil
+}
+
+// AddURL stores a URL. If url.ShortCode is set, it is used as a custom alias and
+// ErrShortCodeTaken is returned when it already exists. Otherwise a short code is generated.
+func (m *MemoryStore) AddURL(_ context.Context, url core.URL) (core.URL, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if url.ShortCode != "" {
+		if _, ok := m.urls[url.ShortCode]; ok {
+			return core.URL{}, fmt.Errorf("store: %w", ErrShortCodeTaken)
+		}
+		return m.insert(url), nil
+	}
+
+	for i := 0; i < maxRetries; i++ {
+		shortCode, err := core.GenerateShortCode()
+		if err != nil {
+			return core.URL{}, fmt.Errorf("store: %w", err)
+		}
+		if _, ok := m.urls[shortCode]; ok {
+			continue
+		}
+		url.ShortCode = shortCode
+		return m.insert(url), nil
+	}
+	return core.URL{}, fmt.Errorf("store: %w", ErrFailedToAddURL)
+}
+
+// AddURLs stores several URLs with generated short codes, and returns them in the same order.
+func (m *MemoryStore) AddURLs(ctx context.Context, urls []core.URL) ([]core.URL, error) {
+	out := make([]core.URL, 0, len(urls))
+	for _, url := range urls {
+		url.ShortCode = ""
+		added, err := m.AddURL(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, added)
+	}
+	return out, nil
+}
+
+// insert must be called with the write lock held.
+func (m *MemoryStore) insert(url core.URL) core.URL {
+	// Postgres stores timestamps with microsecond precision.
+	url.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	url.DisabledAt = nil
+	url.RemainingClicks = nil
+	if url.MaxClicks > 0 {
+		remaining := url.MaxClicks
+		url.RemainingClicks = &remaining
+	}
+	m.urls[url.ShortCode] = url
+	return url
+}
+
+// GetURL retrieves the URL stored for a given short code.
+// It returns ErrURLExpired, ErrURLDisabled or ErrURLUsedUp if the URL exists but can no longer be used.
+func (m *MemoryStore) GetURL(_ context.Context, shortCode string) (core.URL, error) {
+	m.mu.RLock()
+	url, ok := m.urls[shortCode]
+	m.mu.RUnlock()
+
+	if !ok {
+		return core.URL{}, ErrURLNotFound
+	}
+	if url.Disabled() {
+		return core.URL{}, ErrURLDisabled
+	}
+	if url.Expired(time.Now()) {
+		return core.URL{}, ErrURLExpired
+	}
+	if url.UsedUp() {
+		return core.URL{}, ErrURLUsedUp
+	}
+	return url, nil
+}
+
+// TakeClick counts a redirect against the click limit of a URL and returns the number of clicks it has left.
+// It returns ErrURLUsedUp if the URL has no clicks left, or no longer exists.
+func (m *MemoryStore) TakeClick(_ context.Context, shortCode string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	url, ok := m.urls[shortCode]
+	if !ok || url.RemainingClicks == nil || *url.RemainingClicks <= 0 {
+		return 0, ErrURLUsedUp
+	}
+	// Copy the count, as URLs handed out earlier share the pointer.
+	remaining := *url.RemainingClicks - 1
+	url.RemainingClicks = &remaining
+	m.urls[shortCode] = url
+	return remaining, nil
+}
+
+// LookupURL retrieves the URL stored for a given short code, even if it has expired or been disabled.
+func (m *MemoryStore) LookupURL(_ context.Context, shortCode string) (core.URL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	url, ok := m.urls[shortCode]
+	if !ok {
+		return core.URL{}, ErrURLNotFound
+	}
+	return url, nil
+}
+
+// FindURL returns the oldest plain URL stored for a given long URL by the given owner: one that
+// has no start or expiry time, is not password protected, redirects with 302, drops query parameters and has no
+// targeting rules, variants or click limit.
+func (m *MemoryStore) FindURL(_ context.Context, longURL string, owner string) (core.URL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var found *core.URL
+	for _, url := range m.urls {
+		if url.LongURL != longURL || url.Owner != owner || url.ExpiresAt != nil || url.NotBefore != nil || url.Disabled() || url.Protected() ||
+			url.Redirect() != http.StatusFound || url.QueryMode != core.QueryModeOff || url.PerVisitor() || url.ClickLimited() {
+			continue
+		}
+		if found == nil || url.CreatedAt.Before(found.CreatedAt) {
+			found = &url
+		}
+	}
+	if found == nil {
+		return core.URL{}, ErrURLNotFound
+	}
+	return *found, nil
+}
+
+// ListURLs returns the URLs matching the filter, newest first.
+func (m *MemoryStore) ListURLs(_ context.Context, filter core.URLFilter) ([]core.URL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	contains := strings.ToLower(filter.Contains)
+	urls := make([]core.URL, 0)
+	for _, url := range m.urls {
+		if filter.CreatedAfter != nil && url.CreatedAt.Before(*filter.CreatedAfter) {
+			continue
+		}
+		if filter.CreatedBefore != nil && !url.CreatedAt.Before(*filter.CreatedBefore) {
+			continue
+		}
+		if contains != "" && !strings.Contains(strings.ToLower(url.LongURL), contains) {
+			continue
+		}
+		if filter.Owner != "" && url.Owner != filter.Owner {
+			continue
+		}
+		if filter.After != nil && compareURLs(url, *filter.After) >= 0 {
+			continue
+		}
+		urls = append(urls, url)
+	}
+
+	slices.SortFunc(urls, func(a, b core.URL) int {
+		return compareURLs(b, core.URLCursor{CreatedAt: a.CreatedAt, ShortCode: a.ShortCode})
+	})
+	if len(urls) > filter.Limit {
+		urls = urls[:filter.Limit]
+	}
+	return urls, nil
+}
+
+// compareURLs orders a URL against a listing position by creation time, then short code.
+func compareURLs(url core.URL, cursor core.URLCursor) int {
+	if c := url.CreatedAt.Compare(cursor.CreatedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(url.ShortCode, cursor.ShortCode)
+}
+
+// UpdateURL changes the long URL a short code points to.
+func (m *MemoryStore) UpdateURL(_ context.Context, shortCode string, longURL string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	url, ok := m.urls[shortCode]
+	if !ok {
+		return ErrURLNotFound
+	}
+	url.LongURL = longURL
+	m.urls[shortCode] = url
+	return nil
+}
+
+// DeleteURL removes a URL along with its recorded clicks.
+func (m *MemoryStore) DeleteURL(_ context.Context, shortCode string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.urls[shortCode]; !ok {
+		return ErrURLNotFound
+	}
+	delete(m.urls, shortCode)
+	delete(m.clicks, shortCode)
+	return nil
+}
+
+// DisableURL marks a URL as disabled. Disabling an already disabled URL is a no-op.
+func (m *MemoryStore) DisableURL(_ context.Context, shortCode string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	url, ok := m.urls[shortCode]
+	if !ok {
+		return ErrURLNotFound
+	}
+	if url.DisabledAt == nil {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		url.DisabledAt = &now
+		m.urls[shortCode] = url
+	}
+	return nil
+}
+
+// AddClicks records a batch of clicks.
+func (m *MemoryStore) AddClicks(_ context.Context, clicks []core.Click) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, click := range clicks {
+		m.clicks[click.ShortCode] = append(m.clicks[click.ShortCode], click)
+	}
+	return nil
+}
+
+// GetLinkStats returns the total number of clicks for a short code, along with a per-day breakdown
+// of the clicks since the given time and the total per variant.
+func (m *MemoryStore) GetLinkStats(_ context.Context, shortCode string, since time.Time) (core.LinkStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	clicks := m.clicks[shortCode]
+	stats := core.LinkStats{TotalClicks: int64(len(clicks))}
+	perDay := make(map[time.Time]int64)
+	perVariant := make(map[string]int64)
+	for _, click := range clicks {
+		if click.Variant != "" {
+			perVariant[click.Variant]++
+		}
+		if click.ClickedAt.Before(since) {
+			continue
+		}
+		perDay[click.ClickedAt.UTC().Truncate(24*time.Hour)]++
+	}
+	for day, count := range perDay {
+		stats.Daily = append(stats.Daily, core.DailyClicks{Day: day, Clicks: count})
+	}
+	slices.SortFunc(stats.Daily, func(a, b core.DailyClicks) int {
+		return a.Day.Compare(b.Day)
+	})
+	for variant, count := range perVariant {
+		stats.Variants = append(stats.Variants, core.VariantClicks{Variant: variant, Clicks: count})
+	}
+	slices.SortFunc(stats.Variants, func(a, b core.VariantClicks) int {
+		return cmp.Compare(a.Variant, b.Variant)
+	})
+	return stats, nil
+}
+
+// AddAPIKey stores a new API key. It returns ErrAPIKeyNameTaken if an active key already has the same name.
+func (m *MemoryStore) AddAPIKey(_ context.Context, key core.APIKey) (core.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.apiKeys {
+		if existing.Name == key.Name && !existing.Revoked() {
+			return core.APIKey{}, fmt.Errorf("store: %w", ErrAPIKeyNameTaken)
+		}
+	}
+	if _, ok := m.apiKeys[key.ID]; ok {
+		return core.APIKey{}, fmt.Errorf("store: api key id %q already exists", key.ID)
+	}
+	key.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	key.RevokedAt = nil
+	m.apiKeys[key.ID] = key
+	return key, nil
+}
+
+// GetAPIKey retrieves an API key by the hash of its value, including revoked keys.
+func (m *MemoryStore) GetAPIKey(_ context.Context, keyHash string) (core.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, key := range m.apiKeys {
+		if key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return core.APIKey{}, ErrAPIKeyNotFound
+}
+
+// ListAPIKeys returns every API key, including revoked ones, oldest first.
+func (m *MemoryStore) ListAPIKeys(context.Context) ([]core.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]core.APIKey, 0, len(m.apiKeys))
+	for _, key := range m.apiKeys {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b core.APIKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return keys, nil
+}
+
+// RevokeAPIKey marks an API key as revoked. Revoking an already revoked key is a no-op.
+func (m *MemoryStore) RevokeAPIKey(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, ok := m.apiKeys[id]
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		key.RevokedAt = &now
+		m.apiKeys[id] = key
+	}
+	return nil
+}
+
+func (m *MemoryStore) Close() {}
@@ -18,6 +18,8 @@ const (
 	StatusError = "error"
 	// StatusCollision is the label for a key collision during an insert.
 	StatusCollision = "collision"
+	// StatusConflict is the label for an insert rejected because a custom short code is taken.
+	StatusConflict = "conflict"
 )
 
 // Metrics contains the Prometheus collectors for application-specific database metrics.
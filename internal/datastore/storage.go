@@ -0,0 +1,59 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/config"
+	"github.com/ndajr/urlshortener-go/internal/core"
+)
+
+const (
+	// DriverPostgres selects the Postgres backed Store.
+	DriverPostgres = "postgres"
+	// DriverMemory selects the in-memory MemoryStore, which loses all data on restart.
+	DriverMemory = "memory"
+)
+
+// Storage persists short links, their clicks and the API keys used to manage them. Store implements it on top of Postgres
+// and MemoryStore keeps everything in process, e.g. for hermetic tests.
+type Storage interface {
+	Ping(ctx context.Context) error
+	AddURL(ctx context.Context, url core.URL) (core.URL, error)
+	AddURLs(ctx context.Context, urls []core.URL) ([]core.URL, error)
+	GetURL(ctx context.Context, shortCode string) (core.URL, error)
+	LookupURL(ctx context.Context, shortCode string) (core.URL, error)
+	TakeClick(ctx context.Context, shortCode string) (int, error)
+	FindURL(ctx context.Context, longURL string, owner string) (core.URL, error)
+	ListURLs(ctx context.Context, filter core.URLFilter) ([]core.URL, error)
+	UpdateURL(ctx context.Context, shortCode string, longURL string) error
+	DeleteURL(ctx context.Context, shortCode string) error
+	DisableURL(ctx context.Context, shortCode string) error
+	AddClicks(ctx context.Context, clicks []core.Click) error
+	GetLinkStats(ctx context.Context, shortCode string, since time.Time) (core.LinkStats, error)
+	AddAPIKey(ctx context.Context, key core.APIKey) (core.APIKey, error)
+	GetAPIKey(ctx context.Context, keyHash string) (core.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]core.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	Close()
+}
+
+var (
+	_ Storage = Store{}
+	_ Storage = (*MemoryStore)(nil)
+)
+
+// New returns the Storage selected by cfg.DBDriver.
+func New(ctx context.Context, logger *slog.Logger, cfg config.AppSettings) (Storage, error) {
+	switch cfg.DBDriver {
+	case DriverPostgres, "":
+		return NewStore(ctx, logger, cfg)
+	case DriverMemory:
+		logger.Warn("using in-memory datastore, data will be lost on restart")
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("store: unknown db driver %q", cfg.DBDriver)
+	}
+}
@@ -20,6 +20,10 @@ import (
 var (
 	ErrFailedToAddURL = errors.New("failed to add url")
 	ErrURLNotFound    = errors.New("url not found")
+	ErrShortCodeTaken = errors.New("short code already taken")
+	ErrURLExpired     = errors.New("url has expired")
+	ErrURLDisabled    = errors.New("url has been disabled")
+	ErrURLUsedUp      = errors.New("url has no clicks left")
 )
 
 const (
@@ -27,6 +31,8 @@ const (
 	maxRetries = 5
 	// dbConnectTimeout is the timeout for establishing a database connection.
 	dbConnectTimeout = 15 * time.Second
+	// addURLQueryName is the query name label shared by every insert attempt of AddURL.
+	addURLQueryName = "AddURL"
 )
 
 type Store struct {
@@ -122,51 +128,174 @@ func (s Store) Ping(ctx context.Context) error {
 	return nil
 }
 
-// AddURL generates a short code for a URL and stores it in the database.
-// It retries on collision.
-func (s Store) AddURL(ctx context.Context, longURL string) (core.URL, error) {
-	const queryName = "AddURL"
+// AddURL stores a URL in the database. If url.ShortCode is set, it is used as a custom
+// alias and ErrShortCodeTaken is returned when it already exists. Otherwise a short code
+// is generated, retrying on collision.
+func (s Store) AddURL(ctx context.Context, url core.URL) (core.URL, error) {
+	if url.ShortCode != "" {
+		out, err := s.insertURL(ctx, url)
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.dbMetrics.QueryTotal.WithLabelValues(addURLQueryName, StatusConflict).Inc()
+			return core.URL{}, fmt.Errorf("store: %w", ErrShortCodeTaken)
+		}
+		return out, err
+	}
 
 	for i := 0; i < maxRetries; i++ {
 		shortCode, err := core.GenerateShortCode()
 		if err != nil {
 			return core.URL{}, fmt.Errorf("store: %w", err)
 		}
+		url.ShortCode = shortCode
+
+		out, err := s.insertURL(ctx, url)
+		if err == nil {
+			return out, nil
+		}
+
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return core.URL{}, err
+		}
+		// pgx.ErrNoRows is expected on a key collision, so we log and retry.
+		s.dbMetrics.QueryTotal.WithLabelValues(addURLQueryName, StatusCollision).Inc()
+		s.logger.Info("collision detected, generating a new short code", "short_code", shortCode)
+	}
+
+	return core.URL{}, fmt.Errorf("store: %w", ErrFailedToAddURL)
+}
+
+// AddURLs stores several URLs with generated short codes in a single round trip, and
+// returns them in the same order. URLs whose short code collides are retried with new codes.
+func (s Store) AddURLs(ctx context.Context, urls []core.URL) ([]core.URL, error) {
+	const queryName = "AddURLs"
+
+	out := make([]core.URL, len(urls))
+	// pending holds the indexes of the URLs that still need to be inserted.
+	pending := make([]int, len(urls))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	for i := 0; i < maxRetries && len(pending) > 0; i++ {
+		shortCodes := make([]string, 0, len(pending))
+		longURLs := make([]string, 0, len(pending))
+		expiresAt := make([]*time.Time, 0, len(pending))
+		owners := make([]string, 0, len(pending))
+		redirectStatuses := make([]int, 0, len(pending))
+		byShortCode := make(map[string]int, len(pending))
+		for _, idx := range pending {
+			shortCode, err := core.GenerateShortCode()
+			// Regenerate on collisions within the batch itself, so every returned row maps to one input.
+			for _, taken := byShortCode[shortCode]; err == nil && taken; _, taken = byShortCode[shortCode] {
+				shortCode, err = core.GenerateShortCode()
+			}
+			if err != nil {
+				return nil, fmt.Errorf("store: %w", err)
+			}
+			byShortCode[shortCode] = idx
+			shortCodes = append(shortCodes, shortCode)
+			longURLs = append(longURLs, urls[idx].LongURL)
+			expiresAt = append(expiresAt, urls[idx].ExpiresAt)
+			owners = append(owners, urls[idx].Owner)
+			redirectStatuses = append(redirectStatuses, urls[idx].RedirectStatus)
+		}
 
 		start := time.Now()
-		rows, err := s.db.Query(ctx, insertURL, pgx.NamedArgs{
-			"short_code": shortCode,
-			"long_url":   longURL,
+		rows, err := s.db.Query(ctx, insertURLs, pgx.NamedArgs{
+			"short_codes":       shortCodes,
+			"long_urls":         longURLs,
+			"expires_at":        expiresAt,
+			"owners":            owners,
+			"redirect_statuses": redirectStatuses,
 		})
 		if err != nil {
 			s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
 			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
-			return core.URL{}, fmt.Errorf("store: insertURL: %w", err)
+			return nil, fmt.Errorf("store: insertURLs: %w", err)
 		}
 
-		out, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[core.URL])
+		inserted, err := pgx.CollectRows(rows, pgx.RowToStructByName[core.URL])
 		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+		if err != nil {
+			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+			return nil, fmt.Errorf("store: failed to collect inserted rows: %w", err)
+		}
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
 
-		if err == nil {
-			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
-			return out, nil
+		for _, url := range inserted {
+			out[byShortCode[url.ShortCode]] = url
+			delete(byShortCode, url.ShortCode)
 		}
 
-		if errors.Is(err, pgx.ErrNoRows) {
-			// pgx.ErrNoRows is expected on a key collision, so we log and retry.
-			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusCollision).Inc()
+		// Whatever was not inserted collided with an existing short code.
+		pending = pending[:0]
+		for shortCode, idx := range byShortCode {
 			s.logger.Info("collision detected, generating a new short code", "short_code", shortCode)
-		} else {
-			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
-			return core.URL{}, fmt.Errorf("store: failed to collect inserted row: %w", err)
+			pending = append(pending, idx)
+		}
+		if len(pending) > 0 {
+			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusCollision).Add(float64(len(pending)))
 		}
 	}
 
-	return core.URL{}, fmt.Errorf("store: %w", ErrFailedToAddURL)
+	if len(pending) > 0 {
+		return nil, fmt.Errorf("store: %w", ErrFailedToAddURL)
+	}
+	return out, nil
+}
+
+// insertURL inserts a single row. It returns pgx.ErrNoRows if the short code already exists,
+// leaving it to the caller to record whether that was a collision or a conflict.
+func (s Store) insertURL(ctx context.Context, url core.URL) (core.URL, error) {
+	start := time.Now()
+	rows, err := s.db.Query(ctx, insertURL, pgx.NamedArgs{
+		"short_code":      url.ShortCode,
+		"long_url":        url.LongURL,
+		"expires_at":      url.ExpiresAt,
+		"owner":           url.Owner,
+		"password_hash":   url.PasswordHash,
+		"redirect_status": url.RedirectStatus,
+		"query_mode":      string(url.QueryMode),
+		"targeting":       jsonbOrNull(url.Targeting),
+		"variants":        jsonbOrNull(url.Variants),
+		"sticky_variants": url.StickyVariants,
+		"max_clicks":      url.MaxClicks,
+		"not_before":      url.NotBefore,
+		"placeholder_url": url.PlaceholderURL,
+	})
+	if err != nil {
+		s.dbMetrics.QueryDuration.WithLabelValues(addURLQueryName).Observe(time.Since(start).Seconds())
+		s.dbMetrics.QueryTotal.WithLabelValues(addURLQueryName, StatusError).Inc()
+		return core.URL{}, fmt.Errorf("store: insertURL: %w", err)
+	}
+
+	out, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[core.URL])
+	s.dbMetrics.QueryDuration.WithLabelValues(addURLQueryName).Observe(time.Since(start).Seconds())
+
+	switch {
+	case err == nil:
+		s.dbMetrics.QueryTotal.WithLabelValues(addURLQueryName, StatusSuccess).Inc()
+		return out, nil
+	case errors.Is(err, pgx.ErrNoRows):
+		return core.URL{}, pgx.ErrNoRows
+	default:
+		s.dbMetrics.QueryTotal.WithLabelValues(addURLQueryName, StatusError).Inc()
+		return core.URL{}, fmt.Errorf("store: failed to collect inserted row: %w", err)
+	}
 }
 
-// GetURL retrieves the original long URL for a given short code.
-func (s Store) GetURL(ctx context.Context, shortCode string) (string, error) {
+// jsonbOrNull returns the value stored for the targeting rules or variants of a URL: NULL rather
+// than an empty array when there are none, which keeps such URLs plain.
+func jsonbOrNull[T any](values []T) any {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// GetURL retrieves the URL stored for a given short code.
+// It returns ErrURLExpired, ErrURLDisabled or ErrURLUsedUp if the URL exists but can no longer be used.
+func (s Store) GetURL(ctx context.Context, shortCode string) (core.URL, error) {
 	const queryName = "GetURL"
 	start := time.Now()
 	defer func() {
@@ -176,25 +305,233 @@ func (s Store) GetURL(ctx context.Context, shortCode string) (string, error) {
 	rows, err := s.db.Query(ctx, getURL, shortCode)
 	if err != nil {
 		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
-		return "", fmt.Errorf("store: GetURL: %w", err)
+		return core.URL{}, fmt.Errorf("store: GetURL: %w", err)
 	}
 
-	longURL, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[string])
+	url, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[core.URL])
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			// The query was successful but found no rows. This is not a DB error.
 			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
-			return "", ErrURLNotFound
+			return core.URL{}, ErrURLNotFound
 		}
 		// Any other error from CollectExactlyOneRow is a DB error.
 		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
-		return "", fmt.Errorf("store: GetURL: %w", err)
+		return core.URL{}, fmt.Errorf("store: GetURL: %w", err)
 	}
 
 	// Success
 	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
 
-	return longURL, nil
+	if url.Disabled() {
+		return core.URL{}, ErrURLDisabled
+	}
+	if url.Expired(time.Now()) {
+		return core.URL{}, ErrURLExpired
+	}
+	if url.UsedUp() {
+		return core.URL{}, ErrURLUsedUp
+	}
+	return url, nil
+}
+
+// TakeClick counts a redirect against the click limit of a URL and returns the number of clicks it has left.
+// The decrement is a single conditional update, so concurrent redirects never take more clicks than the limit.
+// It returns ErrURLUsedUp if the URL has no clicks left, or no longer exists.
+func (s Store) TakeClick(ctx context.Context, shortCode string) (int, error) {
+	const queryName = "TakeClick"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	var remaining int
+	err := s.db.QueryRow(ctx, takeClick, shortCode).Scan(&remaining)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+			return 0, ErrURLUsedUp
+		}
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return 0, fmt.Errorf("store: TakeClick: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	return remaining, nil
+}
+
+// LookupURL retrieves the URL stored for a given short code, even if it has expired or been disabled.
+// It returns ErrURLNotFound if the short code does not exist.
+func (s Store) LookupURL(ctx context.Context, shortCode string) (core.URL, error) {
+	const queryName = "LookupURL"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	rows, err := s.db.Query(ctx, getURL, shortCode)
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.URL{}, fmt.Errorf("store: LookupURL: %w", err)
+	}
+
+	url, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[core.URL])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+			return core.URL{}, ErrURLNotFound
+		}
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.URL{}, fmt.Errorf("store: LookupURL: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	return url, nil
+}
+
+// FindURL returns the oldest plain URL stored for a given long URL by the given owner: one that
+// has no start or expiry time, is not password protected, redirects with 302, drops query parameters and has no
+// targeting rules, variants or click limit. It returns ErrURLNotFound if the owner never shortened the long URL.
+func (s Store) FindURL(ctx context.Context, longURL string, owner string) (core.URL, error) {
+	const queryName = "FindURL"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	rows, err := s.db.Query(ctx, findURLByLongURL, longURL, owner)
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.URL{}, fmt.Errorf("store: FindURL: %w", err)
+	}
+
+	url, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[core.URL])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+			return core.URL{}, ErrURLNotFound
+		}
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return core.URL{}, fmt.Errorf("store: FindURL: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	return url, nil
+}
+
+// ListURLs returns the URLs matching the filter, newest first.
+func (s Store) ListURLs(ctx context.Context, filter core.URLFilter) ([]core.URL, error) {
+	const queryName = "ListURLs"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	args := pgx.NamedArgs{
+		"created_after":    filter.CreatedAfter,
+		"created_before":   filter.CreatedBefore,
+		"contains":         filter.Contains,
+		"owner":            filter.Owner,
+		"after_created_at": nil,
+		"after_short_code": "",
+		"limit":            filter.Limit,
+	}
+	if filter.After != nil {
+		args["after_created_at"] = filter.After.CreatedAt
+		args["after_short_code"] = filter.After.ShortCode
+	}
+
+	rows, err := s.db.Query(ctx, listURLs, args)
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return nil, fmt.Errorf("store: ListURLs: %w", err)
+	}
+
+	urls, err := pgx.CollectRows(rows, pgx.RowToStructByName[core.URL])
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return nil, fmt.Errorf("store: ListURLs: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	return urls, nil
+}
+
+// UpdateURL changes the long URL a short code points to.
+// It returns ErrURLNotFound if the short code does not exist.
+func (s Store) UpdateURL(ctx context.Context, shortCode string, longURL string) error {
+	const queryName = "UpdateURL"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	tag, err := s.db.Exec(ctx, updateURL, shortCode, longURL)
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return fmt.Errorf("store: UpdateURL: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	if tag.RowsAffected() == 0 {
+		return ErrURLNotFound
+	}
+	return nil
+}
+
+// DeleteURL removes a URL along with its recorded clicks.
+// It returns ErrURLNotFound if the short code does not exist.
+func (s Store) DeleteURL(ctx context.Context, shortCode string) error {
+	const queryName = "DeleteURL"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, deleteURL, shortCode)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrURLNotFound
+		}
+		_, err = tx.Exec(ctx, deleteClicks, shortCode)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrURLNotFound) {
+			s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+			return ErrURLNotFound
+		}
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return fmt.Errorf("store: DeleteURL: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	return nil
+}
+
+// DisableURL marks a URL as disabled. Disabling an already disabled URL is a no-op.
+// It returns ErrURLNotFound if the short code does not exist.
+func (s Store) DisableURL(ctx context.Context, shortCode string) error {
+	const queryName = "DisableURL"
+	start := time.Now()
+	defer func() {
+		s.dbMetrics.QueryDuration.WithLabelValues(queryName).Observe(time.Since(start).Seconds())
+	}()
+
+	tag, err := s.db.Exec(ctx, disableURL, shortCode)
+	if err != nil {
+		s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusError).Inc()
+		return fmt.Errorf("store: DisableURL: %w", err)
+	}
+
+	s.dbMetrics.QueryTotal.WithLabelValues(queryName, StatusSuccess).Inc()
+	if tag.RowsAffected() == 0 {
+		return ErrURLNotFound
+	}
+	return nil
 }
 
 func (s Store) Close() {
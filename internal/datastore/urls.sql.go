@@ -2,14 +2,63 @@ package datastore
 
 const (
 	insertURL = `
-	INSERT INTO urls (short_code, long_url)
-	VALUES (@short_code, @long_url)
+	INSERT INTO urls (short_code, long_url, expires_at, owner, password_hash, redirect_status, query_mode, targeting, variants, sticky_variants, max_clicks, remaining_clicks,
+		not_before, placeholder_url)
+	VALUES (@short_code, @long_url, @expires_at, @owner, @password_hash, @redirect_status, @query_mode, @targeting, @variants, @sticky_variants,
+		@max_clicks, NULLIF(@max_clicks::int, 0), @not_before, @placeholder_url)
+	ON CONFLICT (short_code) DO NOTHING
+	RETURNING *
+	`
+
+	insertURLs = `
+	INSERT INTO urls (short_code, long_url, expires_at, owner, redirect_status)
+	SELECT * FROM unnest(@short_codes::text[], @long_urls::text[], @expires_at::timestamptz[], @owners::text[], @redirect_statuses::int[])
 	ON CONFLICT (short_code) DO NOTHING
 	RETURNING *
 	`
 
 	getURL = `
-	SELECT long_url FROM urls
+	SELECT * FROM urls
+	WHERE short_code = $1
+	`
+
+	findURLByLongURL = `
+	SELECT * FROM urls
+	WHERE long_url = $1 AND owner = $2 AND expires_at IS NULL AND not_before IS NULL AND disabled_at IS NULL
+	AND password_hash = '' AND redirect_status = 302 AND query_mode = '' AND targeting IS NULL AND variants IS NULL AND max_clicks = 0
+	ORDER BY created_at
+	LIMIT 1
+	`
+
+	takeClick = `
+	UPDATE urls SET remaining_clicks = remaining_clicks - 1
+	WHERE short_code = $1 AND remaining_clicks > 0
+	RETURNING remaining_clicks
+	`
+
+	listURLs = `
+	SELECT * FROM urls
+	WHERE (@created_after::timestamptz IS NULL OR created_at >= @created_after)
+	AND (@created_before::timestamptz IS NULL OR created_at < @created_before)
+	AND (@contains::text = '' OR strpos(lower(long_url), lower(@contains)) > 0)
+	AND (@owner::text = '' OR owner = @owner)
+	AND (@after_created_at::timestamptz IS NULL OR (created_at, short_code) < (@after_created_at, @after_short_code))
+	ORDER BY created_at DESC, short_code DESC
+	LIMIT @limit
+	`
+
+	updateURL = `
+	UPDATE urls SET long_url = $2
+	WHERE short_code = $1
+	`
+
+	deleteURL = `
+	DELETE FROM urls
+	WHERE short_code = $1
+	`
+
+	disableURL = `
+	UPDATE urls SET disabled_at = COALESCE(disabled_at, CURRENT_TIMESTAMP)
 	WHERE short_code = $1
 	`
 )
@@ -0,0 +1,40 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// DB looks up the country of client addresses in a local MaxMind-format database, such as
+// GeoLite2 Country or City. It is safe for concurrent use.
+type DB struct {
+	reader *maxminddb.Reader
+}
+
+// Open memory-maps the database file at path.
+func Open(path string) (*DB, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: %w", err)
+	}
+	return &DB{reader: reader}, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country of ip, or "" if the database does not know it.
+func (d *DB) Country(ip net.IP) (string, error) {
+	var record struct {
+		Country struct {
+			ISOCode string `maxminddb:"iso_code"`
+		} `maxminddb:"country"`
+	}
+	if err := d.reader.Lookup(ip, &record); err != nil {
+		return "", fmt.Errorf("geoip: %w", err)
+	}
+	return record.Country.ISOCode, nil
+}
+
+func (d *DB) Close() error {
+	return d.reader.Close()
+}
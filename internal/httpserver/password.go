@@ -0,0 +1,51 @@
+package httpserver
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// passwordField is the name of the password input of the password form.
+const passwordField = "password"
+
+// passwordPage asks visitors of a protected link for its password, and posts it back to the link.
+var passwordPage = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Password required</title>
+<style>
+body { font-family: system-ui, sans-serif; display: flex; justify-content: center; margin-top: 15vh; }
+form { display: flex; flex-direction: column; gap: 0.75rem; width: 20rem; }
+.error { color: #b00020; }
+</style>
+</head>
+<body>
+<form method="post">
+<h1>Password required</h1>
+<p>This link is protected. Enter its password to continue.</p>
+{{if .Error}}<p class="error" role="alert">{{.Error}}</p>{{end}}
+<input type="password" name="{{.Field}}" aria-label="Password" autocomplete="current-password" required autofocus>
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+// passwordForm renders the password form of a protected link, with an optional error message.
+func (s *Server) passwordForm(w http.ResponseWriter, code int, errMsg string) {
+	// Keep the form out of shared caches, and out of frames on other sites.
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	err := passwordPage.Execute(w, struct {
+		Field string
+		Error string
+	}{Field: passwordField, Error: errMsg})
+	if err != nil {
+		s.logger.Error("failed to render password form", "error", err)
+	}
+}
@@ -0,0 +1,55 @@
+package httpserver
+
+import (
+	"html/template"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+)
+
+// pendingPage tells visitors of a link that has not gone live yet when it will.
+var pendingPage = template.Must(template.New("pending").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Not yet available</title>
+<style>
+body { font-family: system-ui, sans-serif; display: flex; justify-content: center; margin-top: 15vh; }
+main { display: flex; flex-direction: column; gap: 0.75rem; width: 32rem; max-width: 90vw; }
+</style>
+</head>
+<body>
+<main>
+<h1>Not yet available</h1>
+<p>This link goes live on <time datetime="{{.NotBefore.Format "2006-01-02T15:04:05Z07:00"}}">{{.NotBefore.Format "January 2, 2006 at 15:04 UTC"}}</time>. Please come back then.</p>
+</main>
+</body>
+</html>
+`))
+
+// notYetAvailable responds to a visit of a link before it goes live: a redirect to its placeholder URL,
+// or else a 503 page saying when to retry. The visit is neither counted nor recorded as a click.
+func (s *Server) notYetAvailable(w http.ResponseWriter, r *http.Request, url core.URL) {
+	// The response changes once the link goes live, so it must not outlive that moment in any cache.
+	w.Header().Set("Cache-Control", "no-store")
+	if url.PlaceholderURL != "" {
+		http.Redirect(w, r, url.PlaceholderURL, http.StatusFound)
+		return
+	}
+
+	retryAfter := int(math.Ceil(time.Until(*url.NotBefore).Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(max(1, retryAfter)))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	err := pendingPage.Execute(w, struct {
+		NotBefore time.Time
+	}{NotBefore: url.NotBefore.UTC()})
+	if err != nil {
+		s.logger.Error("failed to render pending page", "code", url.ShortCode, "error", err)
+	}
+}
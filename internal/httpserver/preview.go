@@ -0,0 +1,100 @@
+package httpserver
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// previewPage shows where a short link leads, so visitors can check it before following it.
+var previewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Link preview</title>
+<style>
+body { font-family: system-ui, sans-serif; display: flex; justify-content: center; margin-top: 15vh; }
+main { display: flex; flex-direction: column; gap: 0.75rem; width: 32rem; max-width: 90vw; }
+code { overflow-wrap: anywhere; }
+a.button { align-self: flex-start; padding: 0.5rem 1rem; border: 1px solid; border-radius: 0.25rem; text-decoration: none; }
+</style>
+</head>
+<body>
+<main>
+<h1>Link preview</h1>
+{{if .Protected}}<p>This link is password protected, so where it leads stays hidden until the password is entered.</p>
+{{else}}{{if .NotBefore}}<p>This link goes live on <time datetime="{{.NotBefore.Format "2006-01-02T15:04:05Z07:00"}}">{{.NotBefore.Format "January 2, 2006 at 15:04 UTC"}}</time>, and where it leads stays hidden until then.</p>
+{{else if .ClickLimited}}<p>This link can only be followed a limited number of times, so where it leads stays hidden until it is followed.</p>
+{{else if .Variants}}<p>This link leads to one of:</p>
+<ul>{{range .Variants}}
+<li><code>{{.Destination}}</code></li>{{end}}
+</ul>
+{{else}}<p>This link leads to:</p>
+<p><code>{{.Destination}}</code></p>
+{{end}}<p>Created on <time datetime="{{.CreatedAt.Format "2006-01-02T15:04:05Z07:00"}}">{{.CreatedAt.Format "January 2, 2006"}}</time>.</p>
+{{end}}<a class="button" href="{{.ShortPath}}">Continue</a>
+</main>
+</body>
+</html>
+`))
+
+// previewHandler serves the preview page of a short code, e.g. /aBcDeF1/preview.
+func (s *Server) previewHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.preview(w, r, r.PathValue("code"))
+	}
+}
+
+// preview renders the destination of the visitor and creation date of a short code instead of redirecting.
+// Continuing goes through the short link, so the visit is handled and recorded like any other.
+func (s *Server) preview(w http.ResponseWriter, r *http.Request, shortCode string) {
+	data := struct {
+		Destination  string
+		Variants     []core.Variant
+		NotBefore    *time.Time
+		CreatedAt    time.Time
+		Protected    bool
+		ClickLimited bool
+		ShortPath    string
+	}{ShortPath: "/" + shortCode}
+
+	url, err := s.server.GetURL(r.Context(), shortCode, "", s.clientIP(r))
+	switch {
+	case err == nil && url.Pending(time.Now()):
+		// Links that have not gone live keep their destination a secret until the launch.
+		notBefore := url.NotBefore.UTC()
+		data.NotBefore = &notBefore
+		data.CreatedAt = url.CreatedAt.UTC()
+	case err == nil && url.ClickLimited():
+		// Click-limited links are often one-time links to secrets, which previews must not give away for free.
+		data.ClickLimited = true
+		data.CreatedAt = url.CreatedAt.UTC()
+	case err == nil:
+		// Split links only show the variants, as picking one here would skew the experiment.
+		visit := s.visit(r, url)
+		if len(url.Variants) > 0 && !url.Targeted(visit) {
+			data.Variants = url.Variants
+		}
+		data.Destination = url.Destination(visit)
+		data.CreatedAt = url.CreatedAt.UTC()
+	case status.Code(err) == codes.Unauthenticated:
+		// Protected links only tell visitors who know the password where they lead.
+		data.Protected = true
+	default:
+		s.lookupError(w, r, shortCode, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := previewPage.Execute(w, data); err != nil {
+		s.logger.Error("failed to render preview page", "code", shortCode, "error", err)
+	}
+}
@@ -0,0 +1,186 @@
+package httpserver
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultQRSize is the width and height in pixels of a QR code when none is requested.
+	defaultQRSize = 256
+	minQRSize     = 64
+	maxQRSize     = 2048
+	// defaultQRMargin is the quiet zone around a QR code in modules, as recommended by the standard.
+	defaultQRMargin = 4
+	maxQRMargin     = 16
+	// qrMaxAge is how long QR codes may be cached. The short URL they encode never changes.
+	qrMaxAge = 24 * time.Hour
+)
+
+// qrLevels maps the error correction levels of the standard to the encoder's, from
+// recovering 7% of the code up to 30% of it.
+var qrLevels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// qrOptions are the query parameters of a QR code request.
+type qrOptions struct {
+	format string // "png" or "svg"
+	size   int    // Width and height in pixels
+	margin int    // Quiet zone in modules
+	level  qrcode.RecoveryLevel
+}
+
+// qrHandler serves a QR code of the short URL of a code, e.g. /aBcDeF1/qr?format=svg&size=512&margin=2&ecc=H.
+func (s *Server) qrHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		shortCode := r.PathValue("code")
+		opts, err := parseQROptions(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// The password of a protected link is asked for once the code is scanned.
+		if _, err := s.server.GetURL(r.Context(), shortCode, "", s.clientIP(r)); err != nil && status.Code(err) != codes.Unauthenticated {
+			s.lookupError(w, r, shortCode, err)
+			return
+		}
+
+		qr, err := qrcode.New(s.shortURL(shortCode), opts.level)
+		if err != nil {
+			s.logger.Error("qrHandler: failed to encode QR code", "code", shortCode, "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		qr.DisableBorder = true
+		modules := qr.Bitmap()
+
+		var buf bytes.Buffer
+		switch opts.format {
+		case "svg":
+			w.Header().Set("Content-Type", "image/svg+xml")
+			err = writeQRSVG(&buf, modules, opts.size, opts.margin)
+		default:
+			// PNG modules are whole pixels, so that scanners see sharp edges.
+			if minSize := len(modules) + 2*opts.margin; opts.size < minSize {
+				http.Error(w, fmt.Sprintf("size must be at least %d for this code and margin", minSize), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "image/png")
+			err = writeQRPNG(&buf, modules, opts.size, opts.margin)
+		}
+		if err != nil {
+			s.logger.Error("qrHandler: failed to render QR code", "code", shortCode, "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(qrMaxAge.Seconds())))
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			s.logger.Error("qrHandler: failed to write QR code", "code", shortCode, "error", err)
+		}
+	}
+}
+
+func parseQROptions(query url.Values) (qrOptions, error) {
+	opts := qrOptions{
+		format: "png",
+		size:   defaultQRSize,
+		margin: defaultQRMargin,
+		level:  qrcode.Medium,
+	}
+	if v := query.Get("format"); v != "" {
+		opts.format = strings.ToLower(v)
+		if opts.format != "png" && opts.format != "svg" {
+			return qrOptions{}, fmt.Errorf("format must be png or svg")
+		}
+	}
+	if v := query.Get("size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < minQRSize || size > maxQRSize {
+			return qrOptions{}, fmt.Errorf("size must be between %d and %d", minQRSize, maxQRSize)
+		}
+		opts.size = size
+	}
+	if v := query.Get("margin"); v != "" {
+		margin, err := strconv.Atoi(v)
+		if err != nil || margin < 0 || margin > maxQRMargin {
+			return qrOptions{}, fmt.Errorf("margin must be between 0 and %d", maxQRMargin)
+		}
+		opts.margin = margin
+	}
+	if v := query.Get("ecc"); v != "" {
+		level, ok := qrLevels[strings.ToUpper(v)]
+		if !ok {
+			return qrOptions{}, fmt.Errorf("ecc must be one of L, M, Q or H")
+		}
+		opts.level = level
+	}
+	return opts, nil
+}
+
+// shortURL returns the absolute URL of a short code on the public URL of the service. It is never built from
+// the Host header, which clients could set to get codes pointing at a host of their own.
+func (s *Server) shortURL(shortCode string) string {
+	return s.publicURL + "/" + url.PathEscape(shortCode)
+}
+
+// writeQRPNG draws the modules as squares of whole pixels, centered in a size by size image.
+// Pixels left over by the rounding are added to the margin.
+func writeQRPNG(w io.Writer, modules [][]bool, size, margin int) error {
+	scale := size / (len(modules) + 2*margin)
+	offset := (size - scale*len(modules)) / 2
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{color.White, color.Black})
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			for py := offset + y*scale; py < offset+(y+1)*scale; py++ {
+				for px := offset + x*scale; px < offset+(x+1)*scale; px++ {
+					img.SetColorIndex(px, py, 1)
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// writeQRSVG draws each horizontal run of dark modules as a rectangle, in a view box one unit per module.
+func writeQRSVG(w io.Writer, modules [][]bool, size, margin int) error {
+	total := len(modules) + 2*margin
+	var path strings.Builder
+	for y, row := range modules {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start+margin, y+margin, x-start, x-start)
+		}
+	}
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="%d" height="%d" fill="#fff"/><path d="%s" fill="#000"/></svg>`,
+		size, size, total, total, total, total, path.String())
+	return err
+}
@@ -1,13 +1,22 @@
 package httpserver
 
 import (
+	"fmt"
+	"net"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// permanentRedirectMaxAge bounds how long clients may cache a permanent redirect, so changes to the link
+// still reach them eventually. Browsers otherwise keep 301s forever.
+const permanentRedirectMaxAge = 24 * time.Hour
+
 func (s *Server) redirectHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Extract the short code from the request URL path.
@@ -21,18 +30,121 @@ func (s *Server) redirectHandler() http.HandlerFunc {
 			return
 		}
 
-		originalURL, err := s.server.GetURL(r.Context(), shortCode)
+		// A trailing "+", which aliases cannot contain, asks for a preview instead of the redirect.
+		if code, ok := strings.CutSuffix(shortCode, "+"); ok {
+			s.preview(w, r, code)
+			return
+		}
+
+		// Protected links post their password back to the same URL from the password form.
+		var password string
+		if r.Method == http.MethodPost {
+			password = r.PostFormValue(passwordField)
+		}
+
+		url, err := s.server.GetURL(r.Context(), shortCode, password, s.clientIP(r))
 		if err != nil {
-			if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
-				http.NotFound(w, r)
+			switch status.Code(err) {
+			case codes.Unauthenticated:
+				if password == "" {
+					s.passwordForm(w, http.StatusOK, "")
+					return
+				}
+				s.passwordForm(w, http.StatusUnauthorized, "Incorrect password, please try again.")
+				return
+			case codes.ResourceExhausted:
+				s.passwordForm(w, http.StatusTooManyRequests, "Too many incorrect passwords, please try again later.")
 				return
 			}
+			s.lookupError(w, r, shortCode, err)
+			return
+		}
+
+		if url.Pending(time.Now()) {
+			s.notYetAvailable(w, r, url)
+			return
+		}
+
+		if err := s.server.TakeClick(r.Context(), url); err != nil {
+			s.lookupError(w, r, shortCode, err)
+			return
+		}
 
-			s.logger.Error("redirectHandler: failed to retrieve URL", "code", shortCode, "error", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		visit := s.visit(r, url)
+		if len(url.Variants) > 0 && !url.Targeted(visit) {
+			visit.Variant = s.variant(w, r, url)
+		}
+		s.recordClick(r, shortCode, visit.Variant)
+		if cc := cacheControl(url); cc != "" {
+			w.Header().Set("Cache-Control", cc)
+		}
+		// A 303 makes the browser follow the redirect of a submitted password form with a GET.
+		destination := url.Destination(visit)
+		if url.Protected() && r.Method == http.MethodPost {
+			http.Redirect(w, r, destination, http.StatusSeeOther)
 			return
 		}
+		http.Redirect(w, r, destination, url.Redirect())
+	}
+}
+
+// cacheControl returns the Cache-Control header of a redirect, if it needs one. Permanent redirects may
+// be cached for permanentRedirectMaxAge, or until the link expires, which skips the clicks served by caches.
+// Redirects of protected and click-limited links are never cached, as that would skip the password or the count,
+// and those that depend on the visitor are only cached by the visitor's browser.
+func cacheControl(url core.URL) string {
+	if url.Protected() || url.ClickLimited() {
+		return "no-store"
+	}
+	if !url.PermanentRedirect() {
+		return ""
+	}
+	maxAge := permanentRedirectMaxAge
+	if url.ExpiresAt != nil {
+		maxAge = min(maxAge, time.Until(*url.ExpiresAt))
+	}
+	visibility := "public"
+	if url.PerVisitor() {
+		visibility = "private"
+	}
+	return fmt.Sprintf("%s, max-age=%d", visibility, max(0, int(maxAge.Seconds())))
+}
+
+// lookupError responds to a failed short code lookup: 404 for unknown codes, 410 for expired,
+// disabled or used up ones, and 500 for anything else.
+func (s *Server) lookupError(w http.ResponseWriter, r *http.Request, shortCode string, err error) {
+	switch status.Code(err) {
+	case codes.NotFound:
+		http.NotFound(w, r)
+	case codes.FailedPrecondition:
+		http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+	default:
+		s.logger.Error("failed to retrieve URL", "code", shortCode, "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// recordClick queues a click event for the short code and the variant it was sent to, if click tracking is enabled.
+func (s *Server) recordClick(r *http.Request, shortCode, variant string) {
+	if s.recorder == nil {
+		return
+	}
+	s.recorder.Record(core.Click{
+		ClickedAt: time.Now(),
+		ShortCode: shortCode,
+		Referrer:  r.Referer(),
+		UserAgent: r.UserAgent(),
+		ClientIP:  s.clientIP(r),
+		Variant:   variant,
+	})
+}
 
-		http.Redirect(w, r, originalURL, http.StatusFound)
+// clientIP returns the originating client address: the peer address, or the X-Forwarded-For entry
+// added by the outermost trusted proxy. Entries left of it come from the client, so they are ignored.
+func (s *Server) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
 	}
+	return core.ClientIP(r.Header.Values("X-Forwarded-For"), host, s.trustedProxies)
 }
@@ -7,10 +7,12 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/ndajr/urlshortener-go/internal/analytics"
 	"github.com/ndajr/urlshortener-go/internal/rpcserver"
 	swaggerui "github.com/swaggest/swgui/v5emb"
 )
@@ -19,14 +21,29 @@ const docsURL = "/docs/"
 
 type Server struct {
 	server     rpcserver.Server
+	recorder   *analytics.Recorder
+	countries  CountryResolver
 	httpServer *http.Server
 	logger     *slog.Logger
+
+	// trustedProxies is the number of proxies in front of the server whose X-Forwarded-For entries are trusted.
+	trustedProxies int
+	// publicURL is the base URL short links are served on, without a trailing slash.
+	publicURL string
 }
 
-func NewServer(server rpcserver.Server, gwmux *runtime.ServeMux, logger *slog.Logger, swaggerJSON []byte) *Server {
+// NewServer creates the HTTP server. The recorder is optional; without it, redirects are not tracked.
+// So are the countries; without them, targeting rules never match on countries.
+// Client addresses are taken from the X-Forwarded-For entries of the trustedProxies proxies in front of it,
+// and short URLs handed out, e.g. in QR codes, are built on publicURL.
+func NewServer(server rpcserver.Server, gwmux *runtime.ServeMux, recorder *analytics.Recorder, countries CountryResolver, trustedProxies int, publicURL string, logger *slog.Logger, swaggerJSON []byte) *Server {
 	s := &Server{
-		server: server,
-		logger: logger,
+		server:         server,
+		recorder:       recorder,
+		countries:      countries,
+		trustedProxies: trustedProxies,
+		publicURL:      strings.TrimSuffix(publicURL, "/"),
+		logger:         logger,
 	}
 	s.httpServer = &http.Server{
 		Handler: s.registerEndpoints(gwmux, swaggerJSON),
@@ -48,7 +65,13 @@ func (s *Server) registerEndpoints(gwmux *runtime.ServeMux, swaggerJSON []byte)
 	})
 	mux.Handle(docsURL, swaggerui.New("URL Shortener API", "/swagger.json", docsURL))
 	mux.Handle("/api/", gwmux)
-	mux.HandleFunc("/", s.redirectHandler())
+
+	// Short links get a mux of their own, as their patterns would conflict with the prefixes above.
+	links := http.NewServeMux()
+	links.HandleFunc("GET /{code}/qr", s.qrHandler())
+	links.HandleFunc("GET /{code}/preview", s.previewHandler())
+	links.HandleFunc("/", s.redirectHandler())
+	mux.Handle("/", links)
 
 	return mux
 }
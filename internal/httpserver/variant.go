@@ -0,0 +1,48 @@
+package httpserver
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+)
+
+const (
+	// variantCookiePrefix starts the name of the cookie keeping a visitor on a variant, followed by the short code.
+	variantCookiePrefix = "variant_"
+	// variantCookieMaxAge is how long a visitor is kept on a variant, from their last visit.
+	variantCookieMaxAge = 90 * 24 * time.Hour
+)
+
+// variant picks the variant of a split URL a visit is sent to, and returns its name. Without sticky variants,
+// each visit picks one at random by weight. With them, a returning visitor is sent to the variant of their
+// cookie, and others pick by a hash of the client, so visitors without cookies mostly stay on theirs too.
+func (s *Server) variant(w http.ResponseWriter, r *http.Request, url core.URL) string {
+	if !url.StickyVariants {
+		return url.PickVariant(rand.Uint64()).Name
+	}
+
+	cookieName := variantCookiePrefix + url.ShortCode
+	variant, ok := core.Variant{}, false
+	if cookie, err := r.Cookie(cookieName); err == nil {
+		variant, ok = url.VariantNamed(cookie.Value)
+	}
+	if !ok {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(url.ShortCode + "\x00" + s.clientIP(r) + "\x00" + r.UserAgent()))
+		variant = url.PickVariant(h.Sum64())
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieName,
+		Value:    variant.Name,
+		Path:     "/" + url.ShortCode,
+		MaxAge:   int(variantCookieMaxAge.Seconds()),
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return variant.Name
+}
@@ -0,0 +1,90 @@
+package httpserver
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+)
+
+// CountryResolver finds the country of a client address, e.g. from a GeoIP database.
+type CountryResolver interface {
+	// Country returns the ISO 3166-1 alpha-2 code of the country of ip, or "" if it is unknown.
+	Country(ip net.IP) (string, error)
+}
+
+// visit describes the visitor of a short link, to match it against the link's targeting rules.
+// The country is only resolved when a rule needs it.
+func (s *Server) visit(r *http.Request, url core.URL) core.Visit {
+	v := core.Visit{
+		Query:    r.URL.Query(),
+		Language: preferredLanguage(r.Header.Get("Accept-Language")),
+	}
+	v.OS, v.Device = classifyUserAgent(r.UserAgent())
+	if s.countries != nil && url.TargetsCountries() {
+		if ip := net.ParseIP(s.clientIP(r)); ip != nil {
+			country, err := s.countries.Country(ip)
+			if err != nil {
+				s.logger.Warn("failed to resolve client country", "error", err)
+			}
+			v.Country = strings.ToUpper(country)
+		}
+	}
+	return v
+}
+
+// classifyUserAgent returns the operating system and device class of a User-Agent header,
+// or empty strings for those it does not recognize.
+func classifyUserAgent(ua string) (os, device string) {
+	switch {
+	case strings.Contains(ua, "iPad"):
+		return core.OSIOS, core.DeviceTablet
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPod"):
+		return core.OSIOS, core.DeviceMobile
+	case strings.Contains(ua, "Android"):
+		// Android tablets leave "Mobile" out of their User-Agent.
+		if strings.Contains(ua, "Mobile") {
+			return core.OSAndroid, core.DeviceMobile
+		}
+		return core.OSAndroid, core.DeviceTablet
+	case strings.Contains(ua, "Windows Phone"):
+		return core.OSWindows, core.DeviceMobile
+	case strings.Contains(ua, "Windows"):
+		return core.OSWindows, core.DeviceDesktop
+	case strings.Contains(ua, "CrOS"):
+		return core.OSChromeOS, core.DeviceDesktop
+	case strings.Contains(ua, "Macintosh"), strings.Contains(ua, "Mac OS X"):
+		return core.OSMacOS, core.DeviceDesktop
+	case strings.Contains(ua, "Linux"), strings.Contains(ua, "X11"):
+		return core.OSLinux, core.DeviceDesktop
+	}
+	return "", ""
+}
+
+// preferredLanguage returns the language tag with the highest quality in an Accept-Language header,
+// in lower case. The first one wins a tie, and the wildcard is ignored.
+func preferredLanguage(header string) string {
+	var best string
+	var bestQuality float64
+	for _, entry := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(entry, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = q
+		}
+		if quality > bestQuality {
+			best, bestQuality = tag, quality
+		}
+	}
+	return best
+}
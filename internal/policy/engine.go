@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Engine checks destinations against the policy last loaded from a file, and reloads it when the file
+// changes, so rules take effect without a restart. It is safe for concurrent use.
+type Engine struct {
+	logger         *slog.Logger
+	path           string
+	reloadInterval time.Duration
+	policy         atomic.Pointer[Policy]
+
+	// mu serializes reloads, and guards data, the file contents the current policy was parsed from.
+	mu   sync.Mutex
+	data []byte
+}
+
+// Load reads the policy file at path. The file must exist and be valid, so a typo cannot start the
+// service without its policy, and the reload interval must be positive.
+func Load(logger *slog.Logger, path string, reloadInterval time.Duration) (*Engine, error) {
+	if reloadInterval <= 0 {
+		return nil, fmt.Errorf("policy: reload interval must be positive, got %s", reloadInterval)
+	}
+	e := &Engine{logger: logger, path: path, reloadInterval: reloadInterval}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Check returns an error with the reason the destination is rejected, or nil if the current policy allows it.
+func (e *Engine) Check(destination string) error {
+	return e.policy.Load().Check(destination)
+}
+
+// Reload reads the policy file again, and switches to its policy if the file changed. An invalid file
+// leaves the current policy in place.
+func (e *Engine) Reload() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		return fmt.Errorf("policy: %w", err)
+	}
+	if e.policy.Load() != nil && bytes.Equal(data, e.data) {
+		return nil
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	e.policy.Store(p)
+	e.data = data
+	e.logger.Info("loaded destination policy", "path", e.path, "allowRules", len(p.Allow), "blockRules", len(p.Block))
+	return nil
+}
+
+// Run starts reloading the policy file every reload interval in the background, until ctx is cancelled.
+func (e *Engine) Run(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(e.reloadInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := e.Reload(); err != nil {
+					e.logger.Error("failed to reload destination policy, keeping the current one", "path", e.path, "error", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
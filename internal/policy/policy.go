@@ -0,0 +1,132 @@
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultBlockReason is returned for destinations matching a block rule without a reason.
+	defaultBlockReason = "blocked by policy"
+	// notAllowedReason is returned for destinations matching none of the allow rules.
+	notAllowedReason = "not on the allowlist"
+)
+
+// Rule matches destinations either by domain or by a regular expression on the whole URL.
+type Rule struct {
+	// Domain is a host name pattern, where * matches any run of characters, e.g. *.example.com.
+	// It does not match the bare domain, so example.com needs a rule of its own.
+	Domain string `yaml:"domain"`
+	// URL is a regular expression matched against the whole destination URL. It is not anchored,
+	// so it needs ^ and $ to match from the start or up to the end.
+	URL string `yaml:"url"`
+	// Reason tells the caller why a destination matching a block rule is rejected.
+	Reason string `yaml:"reason"`
+
+	re *regexp.Regexp
+}
+
+// Policy decides which destinations short links may point to.
+type Policy struct {
+	// Allow limits destinations to those matching one of its rules. Empty allows every destination.
+	Allow []Rule `yaml:"allow"`
+	// Block rejects the destinations matching any of its rules, even allowed ones. The first match gives the reason.
+	Block []Rule `yaml:"block"`
+}
+
+// Parse reads a policy from YAML, e.g.
+//
+//	allow:
+//	  - domain: "*.example.com"
+//	block:
+//	  - domain: "login-example.*"
+//	    reason: known phishing domain
+//	  - url: '\.exe$'
+//	    reason: links to executables are not allowed
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("policy: %w", err)
+	}
+	for i := range p.Allow {
+		if err := p.Allow[i].compile(); err != nil {
+			return nil, fmt.Errorf("policy: allow rule %d: %w", i+1, err)
+		}
+	}
+	for i := range p.Block {
+		if err := p.Block[i].compile(); err != nil {
+			return nil, fmt.Errorf("policy: block rule %d: %w", i+1, err)
+		}
+	}
+	return &p, nil
+}
+
+// compile checks that the rule sets exactly one valid pattern, and prepares it for matching.
+func (r *Rule) compile() error {
+	switch {
+	case r.Domain != "" && r.URL != "":
+		return errors.New("only one of domain or url may be set")
+	case r.Domain != "":
+		r.Domain = strings.ToLower(r.Domain)
+		if _, err := path.Match(r.Domain, ""); err != nil {
+			return fmt.Errorf("invalid domain pattern %q: %w", r.Domain, err)
+		}
+	case r.URL != "":
+		re, err := regexp.Compile(r.URL)
+		if err != nil {
+			return fmt.Errorf("invalid url pattern: %w", err)
+		}
+		r.re = re
+	default:
+		return errors.New("missing domain or url")
+	}
+	return nil
+}
+
+// matches reports whether the rule matches a destination with the given lower case host name.
+func (r Rule) matches(destination, host string) bool {
+	if r.re != nil {
+		return r.re.MatchString(destination)
+	}
+	ok, _ := path.Match(r.Domain, host)
+	return ok
+}
+
+// Check returns an error with the reason the destination is rejected, or nil if the policy allows it.
+// The destination must be an absolute URL.
+func (p *Policy) Check(destination string) error {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return fmt.Errorf("invalid url format: %w", err)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	for _, rule := range p.Block {
+		if rule.matches(destination, host) {
+			reason := rule.Reason
+			if reason == "" {
+				reason = defaultBlockReason
+			}
+			return errors.New(reason)
+		}
+	}
+	if len(p.Allow) == 0 {
+		return nil
+	}
+	for _, rule := range p.Allow {
+		if rule.matches(destination, host) {
+			return nil
+		}
+	}
+	return errors.New(notAllowedReason)
+}
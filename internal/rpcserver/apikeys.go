@@ -0,0 +1,109 @@
+package rpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	"github.com/ndajr/urlshortener-go/internal/datastore"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxAPIKeyNameLength is the longest name or owner an API key can have.
+const maxAPIKeyNameLength = 64
+
+var (
+	scopesFromProto = map[proto.APIKeyScope]core.Scope{
+		proto.APIKeyScope_API_KEY_SCOPE_CREATE: core.ScopeCreate,
+		proto.APIKeyScope_API_KEY_SCOPE_READ:   core.ScopeRead,
+		proto.APIKeyScope_API_KEY_SCOPE_ADMIN:  core.ScopeAdmin,
+	}
+	scopesToProto = map[core.Scope]proto.APIKeyScope{
+		core.ScopeCreate: proto.APIKeyScope_API_KEY_SCOPE_CREATE,
+		core.ScopeRead:   proto.APIKeyScope_API_KEY_SCOPE_READ,
+		core.ScopeAdmin:  proto.APIKeyScope_API_KEY_SCOPE_ADMIN,
+	}
+)
+
+func (s URLShortenerService) CreateAPIKey(ctx context.Context, req *proto.CreateAPIKeyRequest) (*proto.CreateAPIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing name")
+	}
+	if len(name) > maxAPIKeyNameLength {
+		return nil, status.Errorf(codes.InvalidArgument, "name exceeds maximum length of %d characters", maxAPIKeyNameLength)
+	}
+	owner := strings.TrimSpace(req.Owner)
+	if owner == "" {
+		owner = name
+	}
+	if len(owner) > maxAPIKeyNameLength {
+		return nil, status.Errorf(codes.InvalidArgument, "owner exceeds maximum length of %d characters", maxAPIKeyNameLength)
+	}
+	scope, ok := scopesFromProto[req.Scope]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "missing or unknown scope")
+	}
+
+	id, key, err := core.GenerateAPIKey()
+	if err != nil {
+		s.logger.Error("CreateAPIKey internal error", "error", err)
+		return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	apiKey, err := s.db.AddAPIKey(ctx, core.APIKey{ID: id, Name: name, Owner: owner, Scope: scope, KeyHash: core.HashAPIKey(key)})
+	if err != nil {
+		if errors.Is(err, datastore.ErrAPIKeyNameTaken) {
+			return nil, status.Error(codes.AlreadyExists, ErrStoreAPIKeyNameTaken.Error())
+		}
+		s.logger.Error("CreateAPIKey internal error", "error", err)
+		return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	s.logger.Info("created api key", "id", apiKey.ID, "name", apiKey.Name, "owner", apiKey.Owner, "scope", apiKey.Scope)
+	return &proto.CreateAPIKeyResponse{ApiKey: newAPIKeyMessage(apiKey), Key: key}, nil
+}
+
+func (s URLShortenerService) ListAPIKeys(ctx context.Context, _ *proto.ListAPIKeysRequest) (*proto.ListAPIKeysResponse, error) {
+	keys, err := s.db.ListAPIKeys(ctx)
+	if err != nil {
+		s.logger.Error("ListAPIKeys internal error", "error", err)
+		return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	res := &proto.ListAPIKeysResponse{ApiKeys: make([]*proto.APIKey, 0, len(keys))}
+	for _, key := range keys {
+		res.ApiKeys = append(res.ApiKeys, newAPIKeyMessage(key))
+	}
+	return res, nil
+}
+
+func (s URLShortenerService) RevokeAPIKey(ctx context.Context, req *proto.RevokeAPIKeyRequest) (*proto.RevokeAPIKeyResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing id")
+	}
+	if err := s.db.RevokeAPIKey(ctx, req.Id); err != nil {
+		if errors.Is(err, datastore.ErrAPIKeyNotFound) {
+			return nil, status.Error(codes.NotFound, ErrStoreAPIKeyNotFound.Error())
+		}
+		s.logger.Error("RevokeAPIKey internal error", "id", req.Id, "error", err)
+		return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	s.logger.Info("revoked api key", "id", req.Id)
+	return &proto.RevokeAPIKeyResponse{}, nil
+}
+
+func newAPIKeyMessage(key core.APIKey) *proto.APIKey {
+	msg := &proto.APIKey{
+		Id:        key.ID,
+		Name:      key.Name,
+		Owner:     key.Owner,
+		Scope:     scopesToProto[key.Scope],
+		CreatedAt: timestamppb.New(key.CreatedAt),
+	}
+	if key.RevokedAt != nil {
+		msg.RevokedAt = timestamppb.New(*key.RevokedAt)
+	}
+	return msg
+}
@@ -0,0 +1,156 @@
+package rpcserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/ndajr/urlshortener-go/internal/cachestore"
+	"github.com/ndajr/urlshortener-go/internal/config"
+	"github.com/ndajr/urlshortener-go/internal/core"
+	"github.com/ndajr/urlshortener-go/internal/datastore"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrAuthMissingKey = errors.New("missing api key")
+	ErrAuthInvalidKey = errors.New("invalid or revoked api key")
+	ErrAuthForbidden  = errors.New("api key is not allowed to perform this operation")
+	ErrAuthLocked     = errors.New("too many invalid api keys, please try again later")
+)
+
+// bootstrapKeyName is the principal name of the configured bootstrap key.
+const bootstrapKeyName = "bootstrap"
+
+// methodScopes is the scope each RPC requires. RPCs that are not listed require the admin scope.
+var methodScopes = map[string]core.Scope{
+	proto.URLShortenerService_ShortenURL_FullMethodName:      core.ScopeCreate,
+	proto.URLShortenerService_BatchShortenURL_FullMethodName: core.ScopeCreate,
+	proto.URLShortenerService_GetOriginalURL_FullMethodName:  core.ScopeRead,
+	proto.URLShortenerService_ListURLs_FullMethodName:        core.ScopeRead,
+	proto.URLShortenerService_GetLinkStats_FullMethodName:    core.ScopeRead,
+	// The service also checks that the caller owns the link.
+	proto.URLShortenerService_UpdateURL_FullMethodName:  core.ScopeCreate,
+	proto.URLShortenerService_DeleteURL_FullMethodName:  core.ScopeCreate,
+	proto.URLShortenerService_DisableURL_FullMethodName: core.ScopeCreate,
+}
+
+// Authenticator checks the API key of each call against the stored keys.
+type Authenticator struct {
+	logger *slog.Logger
+	db     datastore.Storage
+	// bootstrapHash is the hash of the configured bootstrap key, or empty if there is none.
+	bootstrapHash string
+	// failures counts the invalid keys sent by each client, which is blocked once it has sent too many,
+	// so made-up keys cannot keep the database busy with lookups.
+	failures       *cachestore.AttemptThrottle
+	trustedProxies int
+}
+
+// NewAuthenticator creates an authenticator. The cache is optional; without it, invalid keys are counted per replica.
+// Clients are told apart by their address, behind trustedProxies proxies.
+func NewAuthenticator(logger *slog.Logger, db datastore.Storage, cache *cachestore.Cache, cfg config.Auth, trustedProxies int) Authenticator {
+	a := Authenticator{
+		logger:         logger,
+		db:             db,
+		failures:       cachestore.NewAttemptThrottle(cache, cachestore.AuthFailuresPrefix, cfg.MaxFailures, cfg.FailureWindow),
+		trustedProxies: trustedProxies,
+	}
+	if cfg.BootstrapKey != "" {
+		a.bootstrapHash = core.HashAPIKey(cfg.BootstrapKey)
+	}
+	return a
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor that rejects calls without a valid API key
+// of the required scope, and adds the caller to the context of the others. Health checks are public.
+func (a Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		key := apiKey(md)
+		if key == "" {
+			return nil, status.Error(codes.Unauthenticated, ErrAuthMissingKey.Error())
+		}
+		principal, err := a.checkKey(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		required, ok := methodScopes[info.FullMethod]
+		if !ok {
+			required = core.ScopeAdmin
+		}
+		if !principal.Scope.Allows(required) {
+			return nil, status.Error(codes.PermissionDenied, ErrAuthForbidden.Error())
+		}
+
+		return handler(core.WithPrincipal(ctx, principal), req)
+	}
+}
+
+// checkKey authenticates a key, unless its client has sent too many invalid ones lately. Only invalid keys
+// are counted, so clients with a valid key are never blocked by their own calls.
+func (a Authenticator) checkKey(ctx context.Context, key string) (core.Principal, error) {
+	clientIP := cachestore.ClientIP(ctx, a.trustedProxies)
+	blocked, err := a.failures.Blocked(ctx, clientIP)
+	if err != nil {
+		a.logger.Error("failed to read invalid api key count", "error", err)
+		return core.Principal{}, status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	if blocked {
+		return core.Principal{}, status.Error(codes.ResourceExhausted, ErrAuthLocked.Error())
+	}
+
+	principal, err := a.authenticate(ctx, key)
+	if status.Code(err) == codes.Unauthenticated {
+		if _, countErr := a.failures.Attempt(ctx, clientIP); countErr != nil {
+			a.logger.Warn("failed to count invalid api key", "error", countErr)
+		}
+	}
+	return principal, err
+}
+
+func (a Authenticator) authenticate(ctx context.Context, key string) (core.Principal, error) {
+	keyHash := core.HashAPIKey(key)
+	if a.bootstrapHash != "" && subtle.ConstantTimeCompare([]byte(keyHash), []byte(a.bootstrapHash)) == 1 {
+		return core.Principal{Name: bootstrapKeyName, Owner: bootstrapKeyName, Scope: core.ScopeAdmin}, nil
+	}
+
+	stored, err := a.db.GetAPIKey(ctx, keyHash)
+	if err != nil {
+		if errors.Is(err, datastore.ErrAPIKeyNotFound) {
+			return core.Principal{}, status.Error(codes.Unauthenticated, ErrAuthInvalidKey.Error())
+		}
+		a.logger.Error("failed to read api key from db", "error", err)
+		return core.Principal{}, status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	if stored.Revoked() {
+		return core.Principal{}, status.Error(codes.Unauthenticated, ErrAuthInvalidKey.Error())
+	}
+	return core.Principal{KeyID: stored.ID, Name: stored.Name, Owner: stored.Owner, Scope: stored.Scope}, nil
+}
+
+// apiKey returns the API key sent as a bearer token, or in the x-api-key metadata.
+// The gateway forwards the HTTP Authorization header as the authorization metadata.
+func apiKey(md metadata.MD) string {
+	if auth := md.Get("authorization"); len(auth) > 0 {
+		if token, ok := strings.CutPrefix(auth[0], "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	if key := md.Get("x-api-key"); len(key) > 0 {
+		return strings.TrimSpace(key[0])
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+package rpcserver
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/config"
+	"github.com/ndajr/urlshortener-go/internal/datastore"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// countingResolver resolves every host to a public address after delay, except the hosts in internal and
+// slow, and records the lookups it serves.
+type countingResolver struct {
+	delay    time.Duration
+	internal map[string]bool
+	// slow hosts do not resolve until the lookup is cancelled.
+	slow map[string]bool
+
+	mu       sync.Mutex
+	lookups  map[string]int
+	inFlight int
+	maxSeen  int
+}
+
+func (r *countingResolver) LookupNetIP(ctx context.Context, _, host string) ([]netip.Addr, error) {
+	r.mu.Lock()
+	if r.lookups == nil {
+		r.lookups = make(map[string]int)
+	}
+	r.lookups[host]++
+	r.inFlight++
+	r.maxSeen = max(r.maxSeen, r.inFlight)
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.inFlight--
+		r.mu.Unlock()
+	}()
+
+	if r.slow[host] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	select {
+	case <-time.After(r.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if r.internal[host] {
+		return []netip.Addr{netip.MustParseAddr("10.0.0.7")}, nil
+	}
+	return []netip.Addr{netip.MustParseAddr("93.184.215.14")}, nil
+}
+
+func TestBatchShortenURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver *countingResolver
+		urls     []string
+		timeout  time.Duration
+		assert   func(t *testing.T, resolver *countingResolver, results []*proto.BatchShortenURLResult)
+	}{
+		{
+			name:     "Batch/success_resolving_each_host_once",
+			resolver: &countingResolver{internal: map[string]bool{"intranet.example.net": true}},
+			urls: func() []string {
+				var urls []string
+				for i := range 10 {
+					for _, host := range []string{"example.com", "Docs.example.com", "intranet.example.net"} {
+						urls = append(urls, fmt.Sprintf("https://%s/page-%d", host, i))
+					}
+				}
+				return urls
+			}(),
+			assert: func(t *testing.T, resolver *countingResolver, results []*proto.BatchShortenURLResult) {
+				require.Equal(t, map[string]int{"example.com": 1, "docs.example.com": 1, "intranet.example.net": 1}, resolver.lookups)
+				for i, result := range results {
+					if i%3 == 2 {
+						require.Equal(t, errInternalResolved.Error(), result.GetError(), i)
+						continue
+					}
+					require.NotEmpty(t, result.GetShortCode(), i)
+				}
+			},
+		},
+		{
+			name:     "Batch/success_bounding_concurrent_lookups",
+			resolver: &countingResolver{delay: 20 * time.Millisecond},
+			urls: func() []string {
+				var urls []string
+				for i := range 3 * maxConcurrentLookups {
+					urls = append(urls, fmt.Sprintf("https://site-%d.example.com/", i))
+				}
+				return urls
+			}(),
+			assert: func(t *testing.T, resolver *countingResolver, results []*proto.BatchShortenURLResult) {
+				require.Len(t, resolver.lookups, 3*maxConcurrentLookups)
+				require.LessOrEqual(t, resolver.maxSeen, maxConcurrentLookups)
+				require.Greater(t, resolver.maxSeen, 1)
+				for i, result := range results {
+					require.NotEmpty(t, result.GetShortCode(), i)
+				}
+			},
+		},
+		{
+			name:     "Batch/failure_on_hosts_not_resolved_in_time",
+			resolver: &countingResolver{slow: map[string]bool{"slow.example.net": true, "slower.example.net": true}},
+			urls:     []string{"https://slow.example.net/", "https://example.com/", "https://slower.example.net/"},
+			timeout:  100 * time.Millisecond,
+			assert: func(t *testing.T, resolver *countingResolver, results []*proto.BatchShortenURLResult) {
+				require.Equal(t, errUnresolvedHost.Error(), results[0].GetError())
+				require.NotEmpty(t, results[1].GetShortCode())
+				require.Equal(t, errUnresolvedHost.Error(), results[2].GetError())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			service := NewURLShortenerService(discardLogger, datastore.NewMemoryStore(), nil, config.Shortener{MaxBatchSize: 100}, nil, tt.resolver, 0)
+
+			start := time.Now()
+			res, err := service.BatchShortenURL(ctx, &proto.BatchShortenURLRequest{OriginalUrls: tt.urls})
+			require.NoError(t, err)
+			require.Len(t, res.GetResults(), len(tt.urls))
+			// Slow hosts are given up on together, not one after the other.
+			if tt.timeout > 0 {
+				require.Less(t, time.Since(start), 2*tt.timeout)
+			}
+			tt.assert(t, tt.resolver, res.GetResults())
+		})
+	}
+}
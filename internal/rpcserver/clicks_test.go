@@ -0,0 +1,107 @@
+package rpcserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ndajr/urlshortener-go/internal/datastore"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// failingClicksStore is a store whose click counts fail, as if the database were unreachable.
+type failingClicksStore struct {
+	datastore.Storage
+}
+
+func (failingClicksStore) TakeClick(context.Context, string) (int, error) {
+	return 0, errors.New("connection refused")
+}
+
+func TestTakeClick(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		db     func(db datastore.Storage) datastore.Storage
+		setup  func(t *testing.T, mr *miniredis.Miniredis, shortCode string)
+		assert func(t *testing.T, db datastore.Storage, mr *miniredis.Miniredis, shortCode string, err error)
+	}{
+		{
+			name: "TakeClick/success_seeding_cached_counter",
+			assert: func(t *testing.T, db datastore.Storage, mr *miniredis.Miniredis, shortCode string, err error) {
+				require.NoError(t, err)
+				left, getErr := mr.Get("url:clicks:" + shortCode)
+				require.NoError(t, getErr)
+				require.Equal(t, "2", left)
+			},
+		},
+		{
+			name: "TakeClick/success_counting_in_cache_and_database",
+			setup: func(t *testing.T, mr *miniredis.Miniredis, shortCode string) {
+				require.NoError(t, mr.Set("url:clicks:"+shortCode, "3"))
+			},
+			assert: func(t *testing.T, db datastore.Storage, mr *miniredis.Miniredis, shortCode string, err error) {
+				require.NoError(t, err)
+				left, getErr := mr.Get("url:clicks:" + shortCode)
+				require.NoError(t, getErr)
+				require.Equal(t, "2", left)
+				remaining, takeErr := db.TakeClick(ctx, shortCode)
+				require.NoError(t, takeErr)
+				require.Equal(t, 1, remaining)
+			},
+		},
+		{
+			name: "TakeClick/failure_on_used_up_counter_without_database",
+			setup: func(t *testing.T, mr *miniredis.Miniredis, shortCode string) {
+				require.NoError(t, mr.Set("url:clicks:"+shortCode, "0"))
+			},
+			assert: func(t *testing.T, db datastore.Storage, mr *miniredis.Miniredis, shortCode string, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+				remaining, takeErr := db.TakeClick(ctx, shortCode)
+				require.NoError(t, takeErr)
+				require.Equal(t, 2, remaining)
+			},
+		},
+		{
+			name: "TakeClick/failure_returning_click_after_database_failure",
+			db: func(db datastore.Storage) datastore.Storage {
+				return failingClicksStore{Storage: db}
+			},
+			setup: func(t *testing.T, mr *miniredis.Miniredis, shortCode string) {
+				require.NoError(t, mr.Set("url:clicks:"+shortCode, "3"))
+			},
+			assert: func(t *testing.T, db datastore.Storage, mr *miniredis.Miniredis, shortCode string, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+				left, getErr := mr.Get("url:clicks:" + shortCode)
+				require.NoError(t, getErr)
+				require.Equal(t, "3", left)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			db := datastore.Storage(datastore.NewMemoryStore())
+			created, err := newTestReplica(t, db, mr).ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/invite", MaxClicks: 3})
+			require.NoError(t, err)
+			shortCode := created.GetShortCode()
+			url, err := db.GetURL(ctx, shortCode)
+			require.NoError(t, err)
+
+			if tt.setup != nil {
+				tt.setup(t, mr, shortCode)
+			}
+			service := newTestReplica(t, db, mr)
+			if tt.db != nil {
+				service = newTestReplica(t, tt.db(db), mr)
+			}
+			tt.assert(t, db, mr, shortCode, service.takeClick(ctx, url))
+		})
+	}
+}
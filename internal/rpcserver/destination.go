@@ -0,0 +1,179 @@
+package rpcserver
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// resolveTimeout bounds the lookup of a destination host, so a slow DNS server cannot stall shortening.
+	resolveTimeout = 2 * time.Second
+	// batchResolveTimeout bounds the lookups of all the destination hosts of a batch together.
+	batchResolveTimeout = 5 * time.Second
+	// maxConcurrentLookups bounds the destination hosts of a batch that are looked up at once.
+	maxConcurrentLookups = 16
+)
+
+var (
+	errInternalHost     = errors.New("localhost and internal addresses not allowed")
+	errInternalResolved = errors.New("destination host resolves to an internal address")
+	errUnresolvedHost   = errors.New("could not resolve destination host")
+)
+
+// Resolver looks up the addresses of a host name. *net.Resolver implements it.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// internalPrefixes are the internal ranges netip.Addr has no method for.
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this network", which reaches the local host on most systems
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT, also used for cluster networks
+}
+
+// internalAddr reports whether addr is loopback, private, link-local, such as the cloud metadata
+// address 169.254.169.254, carrier-grade NAT or unspecified. IPv4-mapped IPv6 addresses are checked as IPv4.
+func internalAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		slices.ContainsFunc(internalPrefixes, func(p netip.Prefix) bool { return p.Contains(addr) })
+}
+
+// internalHost reports whether the host name of a URL is localhost or an internal address,
+// in any of the forms browsers accept, e.g. 0x7f000001 or 2130706433 for 127.0.0.1.
+func internalHost(hostname string) bool {
+	hostname = strings.TrimSuffix(strings.ToLower(hostname), ".")
+	if hostname == "localhost" || strings.HasSuffix(hostname, ".localhost") {
+		return true
+	}
+	addr, ok := hostAddr(hostname)
+	return ok && internalAddr(addr)
+}
+
+// hostAddr returns the address of a host name that is an IP address rather than a name to resolve.
+func hostAddr(hostname string) (netip.Addr, bool) {
+	if addr, err := netip.ParseAddr(hostname); err == nil {
+		return addr, true
+	}
+	return parseIPv4Number(hostname)
+}
+
+// parseIPv4Number parses the numeric IPv4 forms that browsers and inet_aton accept besides dotted
+// decimal: 1 to 4 parts in decimal, octal with a leading 0 or hex with a leading 0x, the last part
+// filling the remaining bytes, e.g. 0x7f000001, 2130706433, 0177.0.0.1 or 127.1.
+func parseIPv4Number(hostname string) (netip.Addr, bool) {
+	parts := strings.Split(hostname, ".")
+	if len(parts) > 4 {
+		return netip.Addr{}, false
+	}
+	var n uint64
+	for i, part := range parts {
+		base := 10
+		switch {
+		case strings.HasPrefix(part, "0x"):
+			part, base = part[2:], 16
+			if part == "" {
+				part = "0"
+			}
+		case len(part) > 1 && part[0] == '0':
+			part, base = part[1:], 8
+		}
+		v, err := strconv.ParseUint(part, base, 32)
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		if i < len(parts)-1 {
+			if v > 255 {
+				return netip.Addr{}, false
+			}
+			n = n<<8 | v
+			continue
+		}
+		// The last part fills the bytes the others left.
+		bits := 8 * (5 - len(parts))
+		if v >= 1<<bits {
+			return netip.Addr{}, false
+		}
+		n = n<<bits | v
+	}
+	return netip.AddrFrom4([4]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}), true
+}
+
+// checkResolved looks up a destination host name, and rejects it if it does not resolve or if any
+// of its addresses is internal. Addresses are not checked again, so this guards against names that
+// point inside the network, not against DNS rebinding.
+func (s URLShortenerService) checkResolved(ctx context.Context, hostname string) error {
+	if _, ok := hostAddr(hostname); ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	addrs, err := s.resolver.LookupNetIP(ctx, "ip", hostname)
+	if err != nil || len(addrs) == 0 {
+		s.logger.Info("failed to resolve destination host", "host", hostname, "error", err)
+		return errUnresolvedHost
+	}
+	if slices.ContainsFunc(addrs, internalAddr) {
+		return errInternalResolved
+	}
+	return nil
+}
+
+// resolveHosts looks up the distinct destination hosts of a batch ahead of parseResolvedDestination, so each
+// is resolved once however many destinations share it. At most maxConcurrentLookups run at once, and hosts
+// not resolved within batchResolveTimeout are rejected. Destinations that are invalid are skipped here.
+func (s URLShortenerService) resolveHosts(ctx context.Context, destinations []string) map[string]error {
+	resolved := make(map[string]error)
+	if s.resolver == nil {
+		return resolved
+	}
+	ctx, cancel := context.WithTimeout(ctx, batchResolveTimeout)
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, maxConcurrentLookups)
+		seen = make(map[string]bool)
+	)
+	for _, destination := range destinations {
+		parsedURL, err := parseURL(destination)
+		if err != nil {
+			continue
+		}
+		hostname := destinationHost(parsedURL)
+		if seen[hostname] {
+			continue
+		}
+		seen[hostname] = true
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			err := s.checkResolved(ctx, hostname)
+			mu.Lock()
+			resolved[hostname] = err
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return resolved
+}
+
+// destinationHost returns the host name of a destination that passed parseURL, in lower case.
+func destinationHost(parsedURL string) string {
+	u, _ := url.Parse(parsedURL)
+	return strings.ToLower(u.Hostname())
+}
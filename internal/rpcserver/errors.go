@@ -7,6 +7,8 @@ import (
 	"net/http"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/ndajr/urlshortener-go/internal/cachestore"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -15,11 +17,25 @@ type httpError struct {
 	Message string `json:"message"`
 }
 
+// rateLimitHeaders maps the rate limiter trailers to the HTTP headers sent along with a 429.
+var rateLimitHeaders = map[string]string{
+	cachestore.TrailerRetryAfter: "Retry-After",
+	cachestore.TrailerLimit:      "X-RateLimit-Limit",
+	cachestore.TrailerRemaining:  "X-RateLimit-Remaining",
+}
+
 // NewCustomHTTPErrorHandler creates a custom error handler for the gRPC gateway that marshals
 // errors into the httpError struct, omitting the gRPC status code from the response body.
 func NewCustomHTTPErrorHandler(logger *slog.Logger) runtime.ErrorHandlerFunc {
-	return func(_ context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, _ *http.Request, err error) {
+	return func(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, _ *http.Request, err error) {
 		st := status.Convert(err)
+		if md, ok := runtime.ServerMetadataFromContext(ctx); ok && st.Code() == codes.ResourceExhausted {
+			for trailer, header := range rateLimitHeaders {
+				if v := md.TrailerMD.Get(trailer); len(v) > 0 {
+					w.Header().Set(header, v[0])
+				}
+			}
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(runtime.HTTPStatusFromCode(st.Code()))
 
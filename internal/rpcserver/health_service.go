@@ -13,11 +13,11 @@ var _ healthpb.HealthServer = (*HealthService)(nil)
 
 type HealthService struct {
 	healthpb.UnimplementedHealthServer
-	db    datastore.Store
+	db    datastore.Storage
 	cache *cachestore.Cache
 }
 
-func NewHealthService(db datastore.Store, cache *cachestore.Cache) HealthService {
+func NewHealthService(db datastore.Storage, cache *cachestore.Cache) HealthService {
 	return HealthService{
 		db:    db,
 		cache: cache,
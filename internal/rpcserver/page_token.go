@@ -0,0 +1,33 @@
+package rpcserver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+)
+
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// encodePageToken turns a listing position into an opaque token for clients.
+func encodePageToken(cursor core.URLCursor) (string, error) {
+	buf, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// decodePageToken parses a token produced by encodePageToken.
+func decodePageToken(token string) (core.URLCursor, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return core.URLCursor{}, ErrInvalidPageToken
+	}
+	var cursor core.URLCursor
+	if err := json.Unmarshal(buf, &cursor); err != nil || cursor.ShortCode == "" {
+		return core.URLCursor{}, ErrInvalidPageToken
+	}
+	return cursor, nil
+}
@@ -0,0 +1,126 @@
+package rpcserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ndajr/urlshortener-go/internal/cachestore"
+	"github.com/ndajr/urlshortener-go/internal/config"
+	"github.com/ndajr/urlshortener-go/internal/datastore"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimitHints(t *testing.T) {
+	tests := []struct {
+		name   string
+		assert func(t *testing.T, srv *Server, addr string)
+	}{
+		{
+			name: "RateLimit/success_sending_trailers_on_grpc",
+			assert: func(t *testing.T, _ *Server, addr string) {
+				conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+				require.NoError(t, err)
+				t.Cleanup(func() { _ = conn.Close() })
+				client := proto.NewURLShortenerServiceClient(conn)
+
+				var trailer metadata.MD
+				for range 2 {
+					_, err = client.GetOriginalURL(context.Background(), &proto.GetOriginalURLRequest{ShortCode: "missing"}, grpc.Trailer(&trailer))
+					require.Equal(t, codes.NotFound, status.Code(err))
+				}
+				_, err = client.GetOriginalURL(context.Background(), &proto.GetOriginalURLRequest{ShortCode: "missing"}, grpc.Trailer(&trailer))
+				require.Equal(t, codes.ResourceExhausted, status.Code(err))
+				require.Equal(t, []string{"2"}, trailer.Get(cachestore.TrailerLimit))
+				require.Equal(t, []string{"0"}, trailer.Get(cachestore.TrailerRemaining))
+				requireRetryAfter(t, trailer.Get(cachestore.TrailerRetryAfter)[0])
+			},
+		},
+		{
+			name: "RateLimit/success_sending_headers_on_rest",
+			assert: func(t *testing.T, srv *Server, _ string) {
+				gateway := httptest.NewServer(srv.NewGatewayMux())
+				t.Cleanup(gateway.Close)
+
+				get := func() *http.Response {
+					res, err := http.Get(gateway.URL + "/api/v1/original/missing")
+					require.NoError(t, err)
+					_ = res.Body.Close()
+					return res
+				}
+				for range 2 {
+					require.Equal(t, http.StatusNotFound, get().StatusCode)
+				}
+				res := get()
+				require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+				require.Equal(t, "2", res.Header.Get("X-RateLimit-Limit"))
+				require.Equal(t, "0", res.Header.Get("X-RateLimit-Remaining"))
+				requireRetryAfter(t, res.Header.Get("Retry-After"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, addr := newTestServer(t, &config.RateLimiter{
+				KeyPrefix:    "ratelimit:",
+				Capacity:     2,
+				RefillRate:   1,
+				RefillPeriod: time.Minute,
+			})
+			tt.assert(t, srv, addr)
+		})
+	}
+}
+
+// requireRetryAfter checks a retry hint in seconds, which is within the refill period of newTestServer's callers.
+func requireRetryAfter(t *testing.T, retryAfter string) {
+	t.Helper()
+	seconds, err := strconv.Atoi(retryAfter)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, seconds, 1)
+	require.LessOrEqual(t, seconds, 60)
+}
+
+// newTestServer runs a server with its gateway on a free loopback port, backed by the memory store and an
+// in-memory Redis, and stops it once the test is done.
+func newTestServer(t *testing.T, rateLimiterCfg *config.RateLimiter) (*Server, string) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	cache, err := cachestore.NewCache(context.Background(), discardLogger, config.Redis{Addr: mr.Addr(), UrlPrefix: "url", UrlTTL: time.Hour})
+	require.NoError(t, err)
+	t.Cleanup(cache.Close)
+
+	srv := NewServer(discardLogger, datastore.NewMemoryStore(), cache, config.Shortener{
+		MaxBatchSize:        10,
+		PasswordMaxAttempts: 3,
+		PasswordMaxFailures: 10,
+		PasswordLockout:     time.Minute,
+	}, nil, nil, config.Auth{}, rateLimiterCfg, 0)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	require.NoError(t, srv.Run(ctx, addr, &wg))
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	return &srv, addr
+}
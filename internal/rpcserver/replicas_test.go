@@ -0,0 +1,105 @@
+package rpcserver
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ndajr/urlshortener-go/internal/cachestore"
+	"github.com/ndajr/urlshortener-go/internal/config"
+	"github.com/ndajr/urlshortener-go/internal/datastore"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestReplicaInvalidation(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		change func(t *testing.T, replica URLShortenerService, shortCode string)
+		assert func(res *proto.GetOriginalURLResponse, err error) bool
+	}{
+		{
+			name: "Invalidation/success_after_update",
+			change: func(t *testing.T, replica URLShortenerService, shortCode string) {
+				_, err := replica.UpdateURL(ctx, &proto.UpdateURLRequest{ShortCode: shortCode, OriginalUrl: "https://example.com/updated"})
+				require.NoError(t, err)
+			},
+			assert: func(res *proto.GetOriginalURLResponse, err error) bool {
+				return err == nil && res.GetOriginalUrl() == "https://example.com/updated"
+			},
+		},
+		{
+			name: "Invalidation/success_after_delete",
+			change: func(t *testing.T, replica URLShortenerService, shortCode string) {
+				_, err := replica.DeleteURL(ctx, &proto.DeleteURLRequest{ShortCode: shortCode})
+				require.NoError(t, err)
+			},
+			assert: func(_ *proto.GetOriginalURLResponse, err error) bool {
+				return status.Code(err) == codes.NotFound
+			},
+		},
+		{
+			name: "Invalidation/success_after_disable",
+			change: func(t *testing.T, replica URLShortenerService, shortCode string) {
+				_, err := replica.DisableURL(ctx, &proto.DisableURLRequest{ShortCode: shortCode})
+				require.NoError(t, err)
+			},
+			assert: func(_ *proto.GetOriginalURLResponse, err error) bool {
+				return status.Code(err) == codes.FailedPrecondition
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			db := datastore.NewMemoryStore()
+			replicaA, replicaB := newTestReplica(t, db, mr), newTestReplica(t, db, mr)
+
+			created, err := replicaA.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/original"})
+			require.NoError(t, err)
+			shortCode := created.GetShortCode()
+
+			// The first lookup caches the URL in the background; the next one copies it to the local tier of replica B.
+			_, err = replicaB.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: shortCode})
+			require.NoError(t, err)
+			require.Eventually(t, func() bool { return mr.Exists("url:" + shortCode) }, time.Second, 10*time.Millisecond)
+			res, err := replicaB.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: shortCode})
+			require.NoError(t, err)
+			require.Equal(t, "https://example.com/original", res.GetOriginalUrl())
+
+			tt.change(t, replicaA, shortCode)
+			require.Eventually(t, func() bool {
+				return tt.assert(replicaB.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: shortCode}))
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+// newTestReplica returns a service sharing db and the Redis at mr with the other replicas, with a local tier of its own.
+func newTestReplica(t *testing.T, db datastore.Storage, mr *miniredis.Miniredis) URLShortenerService {
+	t.Helper()
+	cache, err := cachestore.NewCache(context.Background(), discardLogger, config.Redis{
+		Addr:      mr.Addr(),
+		UrlPrefix: "url",
+		UrlTTL:    time.Hour,
+		LocalSize: 100,
+		LocalTTL:  time.Minute,
+	})
+	require.NoError(t, err)
+	t.Cleanup(cache.Close)
+	return NewURLShortenerService(discardLogger, db, cache, config.Shortener{
+		PasswordMaxAttempts: 3,
+		PasswordMaxFailures: 10,
+		PasswordLockout:     time.Minute,
+	}, nil, nil, 0)
+}
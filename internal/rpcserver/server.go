@@ -11,6 +11,7 @@ import (
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/ndajr/urlshortener-go/internal/cachestore"
 	"github.com/ndajr/urlshortener-go/internal/config"
+	"github.com/ndajr/urlshortener-go/internal/core"
 	"github.com/ndajr/urlshortener-go/internal/datastore"
 	proto "github.com/ndajr/urlshortener-go/proto/v1"
 	"google.golang.org/grpc"
@@ -29,23 +30,35 @@ type Server struct {
 
 func NewServer(
 	logger *slog.Logger,
-	db datastore.Store,
+	db datastore.Storage,
 	cache *cachestore.Cache,
+	shortenerCfg config.Shortener,
+	destinations DestinationPolicy,
+	resolver Resolver,
+	authCfg config.Auth,
 	rateLimiterCfg *config.RateLimiter,
+	trustedProxies int,
 ) Server {
-	opts := []grpc.ServerOption{}
+	// Authentication runs first, so the rate limiter can tell callers apart by their API key.
+	// Callers sending invalid keys are throttled by the authenticator itself, before any lookup.
+	interceptors := []grpc.UnaryServerInterceptor{}
+	if authCfg.Enabled {
+		interceptors = append(interceptors, NewAuthenticator(logger, db, cache, authCfg, trustedProxies).UnaryServerInterceptor())
+	} else {
+		logger.Warn("authentication is disabled, anyone can call the API")
+	}
 	if cache != nil && rateLimiterCfg != nil {
-		limiter := cachestore.NewRateLimiter(logger, cache, *rateLimiterCfg)
-		opts = append(opts, grpc.UnaryInterceptor(limiter.UnaryServerInterceptor()))
+		limiter := cachestore.NewRateLimiter(logger, cache, *rateLimiterCfg, trustedProxies)
+		interceptors = append(interceptors, limiter.UnaryServerInterceptor())
 	}
-	grpcServer := grpc.NewServer(opts...)
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
 	grpc_prometheus.Register(grpcServer)
 
 	srv := Server{
 		logger:               logger,
 		grpcServer:           grpcServer,
 		healthService:        NewHealthService(db, cache),
-		urlShorteningService: NewURLShortenerService(logger, db, cache),
+		urlShorteningService: NewURLShortenerService(logger, db, cache, shortenerCfg, destinations, resolver, trustedProxies),
 	}
 
 	srv.registerServices(grpcServer)
@@ -70,7 +83,8 @@ func (s *Server) Run(ctx context.Context, address string, wg *sync.WaitGroup) er
 		}
 	}()
 
-	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	// The gateway marks the calls it relays, so they go by the address of the HTTP client it appends.
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials()), cachestore.GatewayDialOption()}
 	gwConn, err := grpc.NewClient(address, opts...)
 	if err != nil {
 		return err
@@ -115,10 +129,14 @@ func (s *Server) NewGatewayMux() *runtime.ServeMux {
 	return s.gwmux
 }
 
-func (s *Server) GetURL(ctx context.Context, shortCode string) (string, error) {
-	res, err := s.urlShorteningService.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: shortCode})
-	if err != nil {
-		return "", err
-	}
-	return res.OriginalUrl, nil
+// GetURL returns the URL a short code redirects to, for a visitor at clientIP.
+// The password is only checked for protected links.
+func (s *Server) GetURL(ctx context.Context, shortCode, password, clientIP string) (core.URL, error) {
+	return s.urlShorteningService.resolve(ctx, shortCode, password, clientIP)
+}
+
+// TakeClick counts a redirect to the URL against its click limit, if it has one.
+// It fails with FailedPrecondition once the URL is used up.
+func (s *Server) TakeClick(ctx context.Context, url core.URL) error {
+	return s.urlShorteningService.takeClick(ctx, url)
 }
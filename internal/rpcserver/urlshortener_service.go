@@ -5,18 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"net"
+	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
 	"github.com/ndajr/urlshortener-go/internal/cachestore"
+	"github.com/ndajr/urlshortener-go/internal/config"
 	"github.com/ndajr/urlshortener-go/internal/core"
 	"github.com/ndajr/urlshortener-go/internal/datastore"
 	proto "github.com/ndajr/urlshortener-go/proto/v1"
 	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var (
@@ -24,22 +26,84 @@ var (
 	ErrStoreDeadlineExceeded = errors.New("the request has timed out, please try again")
 	ErrStoreInvalidRequest   = errors.New("invalid request or missing data")
 	ErrStoreURLNotFound      = errors.New("url not found")
+	ErrStoreAliasTaken       = errors.New("custom alias is already taken")
+	ErrStoreURLExpired       = errors.New("url has expired")
+	ErrStoreURLDisabled      = errors.New("url has been disabled")
+	ErrStoreURLUsedUp        = errors.New("url has no clicks left")
+	ErrStoreURLPending       = errors.New("url is not yet available")
+	ErrStoreAPIKeyNotFound   = errors.New("api key not found")
+	ErrStoreAPIKeyNameTaken  = errors.New("api key name is already taken")
+	ErrStoreURLNotOwned      = errors.New("url belongs to another owner")
+	ErrStorePasswordRequired = errors.New("url is password protected")
+	ErrStorePasswordInvalid  = errors.New("incorrect password")
+	ErrStorePasswordLocked   = errors.New("too many password attempts, please try again later")
 )
 
+const (
+	// defaultStatsDays is the daily breakdown window used by GetLinkStats when none is requested.
+	defaultStatsDays = 30
+	// maxStatsDays is the largest daily breakdown window GetLinkStats accepts.
+	maxStatsDays = 366
+	// defaultPageSize is the number of links ListURLs returns when no page size is requested.
+	defaultPageSize = 50
+	// maxPageSize is the largest page size ListURLs accepts.
+	maxPageSize = 500
+)
+
+var (
+	queryModesFromProto = map[proto.QueryPassthrough]core.QueryMode{
+		proto.QueryPassthrough_QUERY_PASSTHROUGH_UNSPECIFIED: core.QueryModeOff,
+		proto.QueryPassthrough_QUERY_PASSTHROUGH_KEEP:        core.QueryModeKeep,
+		proto.QueryPassthrough_QUERY_PASSTHROUGH_OVERRIDE:    core.QueryModeOverride,
+		proto.QueryPassthrough_QUERY_PASSTHROUGH_APPEND:      core.QueryModeAppend,
+	}
+	queryModesToProto = map[core.QueryMode]proto.QueryPassthrough{
+		core.QueryModeOff:      proto.QueryPassthrough_QUERY_PASSTHROUGH_UNSPECIFIED,
+		core.QueryModeKeep:     proto.QueryPassthrough_QUERY_PASSTHROUGH_KEEP,
+		core.QueryModeOverride: proto.QueryPassthrough_QUERY_PASSTHROUGH_OVERRIDE,
+		core.QueryModeAppend:   proto.QueryPassthrough_QUERY_PASSTHROUGH_APPEND,
+	}
+)
+
+// DestinationPolicy decides which destinations short links may point to, e.g. from a policy file.
+type DestinationPolicy interface {
+	// Check returns an error with the reason the destination is rejected, or nil if it is allowed.
+	Check(destination string) error
+}
+
 type URLShortenerService struct {
 	proto.UnimplementedURLShortenerServiceServer
-	db     datastore.Store
-	cache  *cachestore.Cache
-	logger *slog.Logger
+	db        datastore.Storage
+	cache     *cachestore.Cache
+	passwords *cachestore.AttemptThrottle
+	// passwordFailures counts the incorrect passwords of each link from all clients together, so guesses
+	// spread over many addresses are limited too.
+	passwordFailures *cachestore.AttemptThrottle
+	cfg              config.Shortener
+	// destinations is optional. Without it, every destination passing parseURL is allowed.
+	destinations DestinationPolicy
+	// resolver is optional. Without it, only destinations with an internal address for a host are rejected,
+	// not those with a name resolving to one.
+	resolver Resolver
+	// trustedProxies is the number of proxies in front of the service whose x-forwarded-for entries are trusted.
+	trustedProxies int
+	logger         *slog.Logger
 }
 
 var _ proto.URLShortenerServiceServer = (*URLShortenerService)(nil)
 
-func NewURLShortenerService(logger *slog.Logger, db datastore.Store, cache *cachestore.Cache) URLShortenerService {
+func NewURLShortenerService(logger *slog.Logger, db datastore.Storage, cache *cachestore.Cache, cfg config.Shortener, destinations DestinationPolicy, resolver Resolver, trustedProxies int) URLShortenerService {
 	return URLShortenerService{
-		logger: logger,
-		db:     db,
-		cache:  cache,
+		logger:         logger,
+		db:             db,
+		cache:          cache,
+		cfg:            cfg,
+		destinations:   destinations,
+		resolver:       resolver,
+		trustedProxies: trustedProxies,
+		// Password attempts are counted per link and client, and failures per link, see checkPassword.
+		passwords:        cachestore.NewAttemptThrottle(cache, cachestore.PasswordAttemptsPrefix, cfg.PasswordMaxAttempts, cfg.PasswordLockout),
+		passwordFailures: cachestore.NewAttemptThrottle(cache, cachestore.LinkPasswordFailuresPrefix, cfg.PasswordMaxFailures, cfg.PasswordLockout),
 	}
 }
 
@@ -47,63 +111,525 @@ func (s URLShortenerService) GetOriginalURL(ctx context.Context, req *proto.GetO
 	if req.ShortCode == "" {
 		return nil, status.Error(codes.InvalidArgument, "missing short code")
 	}
-	url, err := s.getCached(ctx, req.ShortCode)
+	url, err := s.resolve(ctx, req.ShortCode, req.Password, cachestore.ClientIP(ctx, s.trustedProxies))
+	if err != nil {
+		return nil, err
+	}
+	// Links that have not gone live keep their destination a secret until the launch.
+	if url.Pending(time.Now()) {
+		return nil, status.Error(codes.FailedPrecondition, ErrStoreURLPending.Error())
+	}
+	// Reading the destination of a click-limited link is as good as following it, so it costs a click too.
+	if err := s.takeClick(ctx, url); err != nil {
+		return nil, err
+	}
+	return newGetOriginalURLResponse(url), nil
+}
+
+// resolve returns the URL a short code redirects to. A protected URL is only returned along with
+// its password, which is checked against the attempts of the client at clientIP.
+func (s URLShortenerService) resolve(ctx context.Context, shortCode, password, clientIP string) (core.URL, error) {
+	url, err := s.getCached(ctx, shortCode)
 	if err != nil {
-		if errors.Is(err, redis.Nil) {
-			return s.loadCache(ctx, req.ShortCode)
+		if !errors.Is(err, redis.Nil) {
+			s.logger.Warn("cache lookup failed, falling back to database", "shortCode", shortCode, "error", err)
+		}
+		url, err = s.loadCache(ctx, shortCode)
+		if err != nil {
+			return core.URL{}, err
+		}
+	}
+	if url.Disabled() {
+		return core.URL{}, status.Error(codes.FailedPrecondition, ErrStoreURLDisabled.Error())
+	}
+	if url.Expired(time.Now()) {
+		return core.URL{}, status.Error(codes.FailedPrecondition, ErrStoreURLExpired.Error())
+	}
+	if url.Protected() {
+		if err := s.checkPassword(ctx, url, password, clientIP); err != nil {
+			return core.URL{}, err
 		}
-		s.logger.Warn("cache lookup failed, falling back to database", "shortCode", req.ShortCode, "error", err)
-		return s.loadCache(ctx, req.ShortCode)
 	}
 	return url, nil
 }
 
-func (s URLShortenerService) getCached(ctx context.Context, shortCode string) (*proto.GetOriginalURLResponse, error) {
-	if s.cache == nil {
-		return nil, redis.Nil
+// checkPassword lets a client through a protected URL. Attempts are limited per URL and client, and start
+// over after a correct password. As clients can change address, incorrect passwords are also limited per URL,
+// from all clients together, so passwords cannot be guessed by brute force from many addresses either.
+func (s URLShortenerService) checkPassword(ctx context.Context, url core.URL, password, clientIP string) error {
+	if password == "" {
+		return status.Error(codes.Unauthenticated, ErrStorePasswordRequired.Error())
+	}
+	blocked, err := s.passwordFailures.Blocked(ctx, url.ShortCode)
+	if err != nil {
+		s.logger.Error("failed to read password failures", "shortCode", url.ShortCode, "error", err)
+		return status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	if blocked {
+		return status.Error(codes.ResourceExhausted, ErrStorePasswordLocked.Error())
+	}
+	key := url.ShortCode + ":" + clientIP
+	allowed, err := s.passwords.Attempt(ctx, key)
+	if err != nil {
+		s.logger.Error("failed to count password attempt", "shortCode", url.ShortCode, "error", err)
+		return status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	if !allowed {
+		return status.Error(codes.ResourceExhausted, ErrStorePasswordLocked.Error())
+	}
+	ok, err := core.CheckPassword(url.PasswordHash, password)
+	if err != nil {
+		s.logger.Error("failed to check password", "shortCode", url.ShortCode, "error", err)
+		return status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	if !ok {
+		if _, err := s.passwordFailures.Attempt(ctx, url.ShortCode); err != nil {
+			s.logger.Warn("failed to count password failure", "shortCode", url.ShortCode, "error", err)
+		}
+		return status.Error(codes.Unauthenticated, ErrStorePasswordInvalid.Error())
+	}
+	if err := s.passwords.Reset(ctx, key); err != nil {
+		s.logger.Warn("failed to reset password attempts", "shortCode", url.ShortCode, "error", err)
+	}
+	return nil
+}
+
+// takeClick counts a redirect against the click limit of a URL. The database has the final say, with a
+// conditional decrement, so concurrent redirects on any replica never exceed the limit. The cache keeps a
+// shared counter in front of it, which turns away the redirects of used up links without a database round trip.
+func (s URLShortenerService) takeClick(ctx context.Context, url core.URL) error {
+	if !url.ClickLimited() {
+		return nil
+	}
+	seed, taken := false, false
+	if s.cache != nil {
+		left, err := s.cache.TakeClick(ctx, url.ShortCode)
+		switch {
+		case errors.Is(err, redis.Nil):
+			seed = true
+		case err != nil:
+			s.logger.Warn("cache click count failed, falling back to database", "shortCode", url.ShortCode, "error", err)
+		case left == 0:
+			return status.Error(codes.FailedPrecondition, ErrStoreURLUsedUp.Error())
+		default:
+			taken = true
+		}
 	}
 
-	url, err := s.cache.GetURL(ctx, shortCode)
+	remaining, err := s.db.TakeClick(ctx, url.ShortCode)
+	if err != nil && !errors.Is(err, datastore.ErrURLUsedUp) {
+		s.logger.Error("failed to count click", "shortCode", url.ShortCode, "error", err)
+		// The redirect fails, so the click taken off the cached counter must not be lost.
+		if taken {
+			if returnErr := s.cache.ReturnClick(ctx, url.ShortCode); returnErr != nil {
+				s.logger.Warn("failed to return cached click", "shortCode", url.ShortCode, "error", returnErr)
+			}
+		}
+		return status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	if s.cache != nil && seed {
+		if seedErr := s.cache.SeedClicks(ctx, url, remaining); seedErr != nil {
+			s.logger.Warn("failed to seed cached click count", "shortCode", url.ShortCode, "error", seedErr)
+		}
+	}
 	if err != nil {
-		return nil, err
+		return status.Error(codes.FailedPrecondition, ErrStoreURLUsedUp.Error())
 	}
+	if remaining == 0 {
+		// Lookups of the used up URL, e.g. for its QR code, should stop finding it in the cache too.
+		if evictErr := s.evict(ctx, url.ShortCode); evictErr != nil {
+			return evictErr
+		}
+	}
+	return nil
+}
 
-	return &proto.GetOriginalURLResponse{OriginalUrl: url}, nil
+func (s URLShortenerService) getCached(ctx context.Context, shortCode string) (core.URL, error) {
+	if s.cache == nil {
+		return core.URL{}, redis.Nil
+	}
+	return s.cache.GetURL(ctx, shortCode)
 }
 
-func (s URLShortenerService) loadCache(ctx context.Context, shortCode string) (*proto.GetOriginalURLResponse, error) {
+func (s URLShortenerService) loadCache(ctx context.Context, shortCode string) (core.URL, error) {
+	// The version is read before the database, so that if a change evicts the URL in between,
+	// the row read here is kept out of the cache.
+	var version int64
+	cacheable := s.cache != nil
+	if cacheable {
+		var err error
+		if version, err = s.cache.Version(ctx, shortCode); err != nil {
+			s.logger.Warn("failed to read cache version, not caching url", "shortCode", shortCode, "error", err)
+			cacheable = false
+		}
+	}
+
 	url, err := s.db.GetURL(ctx, shortCode)
 	if err != nil {
 		if errors.Is(err, datastore.ErrURLNotFound) {
-			return nil, status.Error(codes.NotFound, ErrStoreURLNotFound.Error())
+			return core.URL{}, status.Error(codes.NotFound, ErrStoreURLNotFound.Error())
+		}
+		if errors.Is(err, datastore.ErrURLExpired) {
+			return core.URL{}, status.Error(codes.FailedPrecondition, ErrStoreURLExpired.Error())
+		}
+		if errors.Is(err, datastore.ErrURLDisabled) {
+			return core.URL{}, status.Error(codes.FailedPrecondition, ErrStoreURLDisabled.Error())
+		}
+		if errors.Is(err, datastore.ErrURLUsedUp) {
+			return core.URL{}, status.Error(codes.FailedPrecondition, ErrStoreURLUsedUp.Error())
 		}
 		s.logger.Error("failed to read url from db", "shortCode", shortCode, "error", err)
-		return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+		return core.URL{}, status.Error(codes.Internal, ErrStoreInternal.Error())
 	}
 
-	if s.cache == nil {
-		return &proto.GetOriginalURLResponse{OriginalUrl: url}, nil
+	// Protected URLs are never cached, so the cache cannot serve them without their password.
+	if !cacheable || url.Protected() {
+		return url, nil
 	}
 
 	go func() {
 		bgCtx := context.WithoutCancel(ctx)
 		bgCtx, cancel := context.WithTimeout(bgCtx, 2*time.Second)
 		defer cancel()
-		if err := s.cache.SetURL(bgCtx, shortCode, url); err != nil {
+		if err := s.cache.SetURL(bgCtx, url, version); err != nil {
 			s.logger.Error("Failed to update cache in background", "key", shortCode, "error", err)
 		}
 	}()
 
-	return &proto.GetOriginalURLResponse{OriginalUrl: url}, nil
+	return url, nil
+}
+
+func newGetOriginalURLResponse(url core.URL) *proto.GetOriginalURLResponse {
+	res := &proto.GetOriginalURLResponse{OriginalUrl: url.LongURL}
+	if url.ExpiresAt != nil {
+		res.ExpiresAt = timestamppb.New(*url.ExpiresAt)
+	}
+	return res
+}
+
+func (s URLShortenerService) ListURLs(ctx context.Context, req *proto.ListURLsRequest) (*proto.ListURLsResponse, error) {
+	pageSize := int(req.PageSize)
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize < 0 || pageSize > maxPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be between 1 and %d", maxPageSize)
+	}
+
+	// Fetch one extra row to find out whether there is a next page.
+	filter := core.URLFilter{
+		Contains: req.DestinationContains,
+		Owner:    req.Owner,
+		Limit:    pageSize + 1,
+	}
+	// Callers that cannot see every owner's links only list their own.
+	if principal, ok := core.PrincipalFromContext(ctx); ok && !principal.CanManage(filter.Owner) {
+		if req.Owner != "" {
+			return nil, status.Error(codes.PermissionDenied, ErrStoreURLNotOwned.Error())
+		}
+		filter.Owner = principal.Owner
+	}
+	if req.CreatedAfter != nil {
+		t := req.CreatedAfter.AsTime()
+		filter.CreatedAfter = &t
+	}
+	if req.CreatedBefore != nil {
+		t := req.CreatedBefore.AsTime()
+		filter.CreatedBefore = &t
+	}
+	if req.PageToken != "" {
+		cursor, err := decodePageToken(req.PageToken)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		filter.After = &cursor
+	}
+
+	urls, err := s.db.ListURLs(ctx, filter)
+	if err != nil {
+		s.logger.Error("ListURLs internal error", "error", err)
+		return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+
+	res := &proto.ListURLsResponse{}
+	if len(urls) > pageSize {
+		urls = urls[:pageSize]
+		last := urls[len(urls)-1]
+		res.NextPageToken, err = encodePageToken(core.URLCursor{CreatedAt: last.CreatedAt, ShortCode: last.ShortCode})
+		if err != nil {
+			s.logger.Error("ListURLs failed to encode page token", "error", err)
+			return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+		}
+	}
+	res.Urls = make([]*proto.URL, 0, len(urls))
+	for _, url := range urls {
+		res.Urls = append(res.Urls, newURLMessage(url))
+	}
+	return res, nil
+}
+
+func (s URLShortenerService) GetLinkStats(ctx context.Context, req *proto.GetLinkStatsRequest) (*proto.GetLinkStatsResponse, error) {
+	if req.ShortCode == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing short code")
+	}
+	days := req.Days
+	if days == 0 {
+		days = defaultStatsDays
+	}
+	if days < 0 || days > maxStatsDays {
+		return nil, status.Errorf(codes.InvalidArgument, "days must be between 1 and %d", maxStatsDays)
+	}
+
+	// Expired and disabled links keep their stats, so only a missing link is an error here.
+	url, err := s.db.LookupURL(ctx, req.ShortCode)
+	if err != nil {
+		if errors.Is(err, datastore.ErrURLNotFound) {
+			return nil, status.Error(codes.NotFound, ErrStoreURLNotFound.Error())
+		}
+		s.logger.Error("failed to read url from db", "shortCode", req.ShortCode, "error", err)
+		return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	if principal, ok := core.PrincipalFromContext(ctx); ok && !principal.CanManage(url.Owner) {
+		return nil, status.Error(codes.PermissionDenied, ErrStoreURLNotOwned.Error())
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, -int(days-1))
+	stats, err := s.db.GetLinkStats(ctx, req.ShortCode, since)
+	if err != nil {
+		s.logger.Error("GetLinkStats internal error", "shortCode", req.ShortCode, "error", err)
+		return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+
+	res := &proto.GetLinkStatsResponse{
+		TotalClicks: stats.TotalClicks,
+		DailyClicks: make([]*proto.DailyClicks, 0, len(stats.Daily)),
+	}
+	for _, d := range stats.Daily {
+		res.DailyClicks = append(res.DailyClicks, &proto.DailyClicks{
+			Date:   d.Day.Format(time.DateOnly),
+			Clicks: d.Clicks,
+		})
+	}
+	for _, v := range stats.Variants {
+		res.VariantClicks = append(res.VariantClicks, &proto.VariantClicks{
+			Variant: v.Variant,
+			Clicks:  v.Clicks,
+		})
+	}
+	return res, nil
+}
+
+func (s URLShortenerService) UpdateURL(ctx context.Context, req *proto.UpdateURLRequest) (*proto.UpdateURLResponse, error) {
+	if req.ShortCode == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing short code")
+	}
+	parsedURL, err := s.parseDestination(ctx, req.OriginalUrl)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.authorizeOwner(ctx, req.ShortCode); err != nil {
+		return nil, err
+	}
+	if err := s.db.UpdateURL(ctx, req.ShortCode, parsedURL); err != nil {
+		if errors.Is(err, datastore.ErrURLNotFound) {
+			return nil, status.Error(codes.NotFound, ErrStoreURLNotFound.Error())
+		}
+		s.logger.Error("UpdateURL internal error", "shortCode", req.ShortCode, "error", err)
+		return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	if err := s.evict(ctx, req.ShortCode); err != nil {
+		return nil, err
+	}
+	return &proto.UpdateURLResponse{OriginalUrl: parsedURL}, nil
+}
+
+func (s URLShortenerService) DeleteURL(ctx context.Context, req *proto.DeleteURLRequest) (*proto.DeleteURLResponse, error) {
+	if req.ShortCode == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing short code")
+	}
+	if err := s.authorizeOwner(ctx, req.ShortCode); err != nil {
+		return nil, err
+	}
+	err := s.db.DeleteURL(ctx, req.ShortCode)
+	if err != nil && !errors.Is(err, datastore.ErrURLNotFound) {
+		s.logger.Error("DeleteURL internal error", "shortCode", req.ShortCode, "error", err)
+		return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	// Evict even if the row is already gone, so retrying after a failed eviction still clears the cache.
+	if evictErr := s.evict(ctx, req.ShortCode); evictErr != nil {
+		return nil, evictErr
+	}
+	if err != nil {
+		return nil, status.Error(codes.NotFound, ErrStoreURLNotFound.Error())
+	}
+	return &proto.DeleteURLResponse{}, nil
+}
+
+func (s URLShortenerService) DisableURL(ctx context.Context, req *proto.DisableURLRequest) (*proto.DisableURLResponse, error) {
+	if req.ShortCode == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing short code")
+	}
+	if err := s.authorizeOwner(ctx, req.ShortCode); err != nil {
+		return nil, err
+	}
+	if err := s.db.DisableURL(ctx, req.ShortCode); err != nil {
+		if errors.Is(err, datastore.ErrURLNotFound) {
+			return nil, status.Error(codes.NotFound, ErrStoreURLNotFound.Error())
+		}
+		s.logger.Error("DisableURL internal error", "shortCode", req.ShortCode, "error", err)
+		return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	if err := s.evict(ctx, req.ShortCode); err != nil {
+		return nil, err
+	}
+	return &proto.DisableURLResponse{}, nil
+}
+
+// authorizeOwner checks that the caller may change the link of a short code. Missing links are
+// left for the caller to report. Without authentication, every caller may change every link.
+func (s URLShortenerService) authorizeOwner(ctx context.Context, shortCode string) error {
+	principal, ok := core.PrincipalFromContext(ctx)
+	if !ok || principal.Scope == core.ScopeAdmin {
+		return nil
+	}
+	url, err := s.db.LookupURL(ctx, shortCode)
+	if err != nil {
+		if errors.Is(err, datastore.ErrURLNotFound) {
+			return nil
+		}
+		s.logger.Error("failed to read url from db", "shortCode", shortCode, "error", err)
+		return status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	if !principal.CanManage(url.Owner) {
+		return status.Error(codes.PermissionDenied, ErrStoreURLNotOwned.Error())
+	}
+	return nil
+}
+
+// evict removes a short code from the cache, so changes to a link take effect immediately
+// instead of after the cache TTL.
+func (s URLShortenerService) evict(ctx context.Context, shortCode string) error {
+	if s.cache == nil {
+		return nil
+	}
+	if err := s.cache.DeleteURL(ctx, shortCode); err != nil {
+		s.logger.Error("failed to evict url from cache", "shortCode", shortCode, "error", err)
+		return status.Error(codes.Internal, ErrStoreInternal.Error())
+	}
+	return nil
 }
 
 func (s URLShortenerService) ShortenURL(ctx context.Context, req *proto.ShortenURLRequest) (*proto.ShortenURLResponse, error) {
-	parsedURL, err := parseURL(req.OriginalUrl)
+	parsedURL, err := s.parseDestination(ctx, req.OriginalUrl)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.CustomAlias != "" {
+		if aliasErr := core.ValidateAlias(req.CustomAlias); aliasErr != nil {
+			return nil, status.Error(codes.InvalidArgument, aliasErr.Error())
+		}
+	}
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		t, tsErr := parseFutureTime("expires_at", req.ExpiresAt)
+		if tsErr != nil {
+			return nil, status.Error(codes.InvalidArgument, tsErr.Error())
+		}
+		expiresAt = &t
+	}
+	var notBefore *time.Time
+	if req.NotBefore != nil {
+		t, tsErr := parseFutureTime("not_before", req.NotBefore)
+		if tsErr != nil {
+			return nil, status.Error(codes.InvalidArgument, tsErr.Error())
+		}
+		if expiresAt != nil && !t.Before(*expiresAt) {
+			return nil, status.Error(codes.InvalidArgument, "not_before must be before expires_at")
+		}
+		notBefore = &t
+	}
+	var placeholderURL string
+	if req.PlaceholderUrl != "" {
+		if notBefore == nil {
+			return nil, status.Error(codes.InvalidArgument, "placeholder_url requires not_before")
+		}
+		placeholderURL, err = s.parseDestination(ctx, req.PlaceholderUrl)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "placeholder_url: "+err.Error())
+		}
+	}
+	redirectStatus := http.StatusFound
+	if req.RedirectStatus != 0 {
+		redirectStatus = int(req.RedirectStatus)
+		if statusErr := core.ValidateRedirectStatus(redirectStatus); statusErr != nil {
+			return nil, status.Error(codes.InvalidArgument, statusErr.Error())
+		}
+	}
+	queryMode, ok := queryModesFromProto[req.QueryPassthrough]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown query_passthrough")
+	}
+	targeting, err := s.parseTargeting(ctx, req.Targeting)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
-	url, err := s.db.AddURL(ctx, parsedURL)
+	if req.MaxClicks < 0 || req.MaxClicks > core.MaxClicks {
+		return nil, status.Error(codes.InvalidArgument, core.ErrMaxClicks.Error())
+	}
+	var variants []core.Variant
+	if len(req.Variants) > 0 {
+		variants, err = s.parseVariants(ctx, req.Variants)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	} else if req.StickyVariants {
+		return nil, status.Error(codes.InvalidArgument, "sticky_variants requires variants")
+	}
+	var passwordHash string
+	if req.Password != "" {
+		passwordHash, err = core.HashPassword(req.Password)
+		if errors.Is(err, core.ErrPasswordLength) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err != nil {
+			s.logger.Error("ShortenURL internal error", "error", err)
+			return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+		}
+	}
+	// Only plain links are deduplicated: a custom alias, an expiry, a start time, a password, another redirect
+	// status, a query passthrough, targeting rules, variants or a click limit ask for a distinct link.
+	plain := passwordHash == "" && redirectStatus == http.StatusFound && queryMode == core.QueryModeOff &&
+		len(targeting) == 0 && len(variants) == 0 && req.MaxClicks == 0
+	if s.deduplicate(req) && req.CustomAlias == "" && expiresAt == nil && notBefore == nil && plain {
+		existing, findErr := s.db.FindURL(ctx, parsedURL, owner(ctx))
+		if findErr == nil {
+			return &proto.ShortenURLResponse{ShortCode: existing.ShortCode, Reused: true}, nil
+		}
+		if !errors.Is(findErr, datastore.ErrURLNotFound) {
+			s.logger.Error("ShortenURL internal error", "error", findErr)
+			return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+		}
+	}
+	url, err := s.db.AddURL(ctx, core.URL{
+		ShortCode:      req.CustomAlias,
+		LongURL:        parsedURL,
+		ExpiresAt:      expiresAt,
+		Owner:          owner(ctx),
+		PasswordHash:   passwordHash,
+		RedirectStatus: redirectStatus,
+		QueryMode:      queryMode,
+		Targeting:      targeting,
+		Variants:       variants,
+		StickyVariants: req.StickyVariants,
+		MaxClicks:      int(req.MaxClicks),
+		NotBefore:      notBefore,
+		PlaceholderURL: placeholderURL,
+	})
 	if err != nil {
+		if errors.Is(err, datastore.ErrShortCodeTaken) {
+			return nil, status.Error(codes.AlreadyExists, ErrStoreAliasTaken.Error())
+		}
 		if errors.Is(err, datastore.ErrFailedToAddURL) {
 			return nil, status.Error(codes.DeadlineExceeded, ErrStoreDeadlineExceeded.Error())
 		}
@@ -113,6 +639,212 @@ func (s URLShortenerService) ShortenURL(ctx context.Context, req *proto.ShortenU
 	return &proto.ShortenURLResponse{ShortCode: url.ShortCode}, nil
 }
 
+func newURLMessage(url core.URL) *proto.URL {
+	msg := &proto.URL{
+		ShortCode:         url.ShortCode,
+		OriginalUrl:       url.LongURL,
+		CreatedAt:         timestamppb.New(url.CreatedAt),
+		Owner:             url.Owner,
+		PasswordProtected: url.Protected(),
+		RedirectStatus:    int32(url.Redirect()),
+		QueryPassthrough:  queryModesToProto[url.QueryMode],
+		Targeting:         targetingToProto(url.Targeting),
+		Variants:          variantsToProto(url.Variants),
+		StickyVariants:    url.StickyVariants,
+		MaxClicks:         int32(url.MaxClicks),
+		PlaceholderUrl:    url.PlaceholderURL,
+	}
+	if url.RemainingClicks != nil {
+		remaining := int32(*url.RemainingClicks)
+		msg.RemainingClicks = &remaining
+	}
+	if url.ExpiresAt != nil {
+		msg.ExpiresAt = timestamppb.New(*url.ExpiresAt)
+	}
+	if url.DisabledAt != nil {
+		msg.DisabledAt = timestamppb.New(*url.DisabledAt)
+	}
+	if url.NotBefore != nil {
+		msg.NotBefore = timestamppb.New(*url.NotBefore)
+	}
+	return msg
+}
+
+// BatchShortenURL shortens every valid URL of the request with a single insert. Invalid URLs
+// get a per-item error instead of failing the whole batch. Deduplication does not apply.
+func (s URLShortenerService) BatchShortenURL(ctx context.Context, req *proto.BatchShortenURLRequest) (*proto.BatchShortenURLResponse, error) {
+	if len(req.OriginalUrls) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "missing original urls")
+	}
+	if len(req.OriginalUrls) > s.cfg.MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d urls can be shortened at once", s.cfg.MaxBatchSize)
+	}
+
+	results := make([]*proto.BatchShortenURLResult, len(req.OriginalUrls))
+	urls := make([]core.URL, 0, len(req.OriginalUrls))
+	// indexes maps each entry of urls back to its position in the request.
+	indexes := make([]int, 0, len(req.OriginalUrls))
+	urlOwner := owner(ctx)
+	// Batches often link to a handful of sites, so each host is looked up once, several at a time.
+	resolved := s.resolveHosts(ctx, req.OriginalUrls)
+	for i, originalURL := range req.OriginalUrls {
+		parsedURL, err := s.parseResolvedDestination(ctx, originalURL, resolved)
+		if err != nil {
+			results[i] = &proto.BatchShortenURLResult{Error: err.Error()}
+			continue
+		}
+		urls = append(urls, core.URL{LongURL: parsedURL, Owner: urlOwner, RedirectStatus: http.StatusFound})
+		indexes = append(indexes, i)
+	}
+
+	if len(urls) > 0 {
+		added, err := s.db.AddURLs(ctx, urls)
+		if err != nil {
+			if errors.Is(err, datastore.ErrFailedToAddURL) {
+				return nil, status.Error(codes.DeadlineExceeded, ErrStoreDeadlineExceeded.Error())
+			}
+			s.logger.Error("BatchShortenURL internal error", "error", err)
+			return nil, status.Error(codes.Internal, ErrStoreInternal.Error())
+		}
+		for j, url := range added {
+			results[indexes[j]] = &proto.BatchShortenURLResult{ShortCode: url.ShortCode}
+		}
+	}
+
+	return &proto.BatchShortenURLResponse{Results: results}, nil
+}
+
+// owner returns the owner recorded on the links created by the caller.
+// It is empty when authentication is disabled.
+func owner(ctx context.Context) string {
+	principal, _ := core.PrincipalFromContext(ctx)
+	return principal.Owner
+}
+
+// deduplicate reports whether ShortenURL should reuse an existing short code,
+// letting the request override the server-wide setting.
+func (s URLShortenerService) deduplicate(req *proto.ShortenURLRequest) bool {
+	if req.Deduplicate != nil {
+		return *req.Deduplicate
+	}
+	return s.cfg.Deduplicate
+}
+
+// parseFutureTime checks that the timestamp of the named field is valid and in the future.
+func parseFutureTime(field string, ts *timestamppb.Timestamp) (time.Time, error) {
+	if err := ts.CheckValid(); err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %w", field, err)
+	}
+	t := ts.AsTime()
+	if !t.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("%s must be in the future", field)
+	}
+	return t, nil
+}
+
+// parseTargeting checks the targeting rules of a request, and converts them to the stored ones.
+func (s URLShortenerService) parseTargeting(ctx context.Context, rules []*proto.TargetingRule) ([]core.TargetingRule, error) {
+	if len(rules) > core.MaxTargetingRules {
+		return nil, core.ErrTargetingRules
+	}
+	var out []core.TargetingRule
+	for i, rule := range rules {
+		if strings.TrimSpace(rule.Destination) == "" {
+			return nil, fmt.Errorf("targeting rule %d: missing destination", i+1)
+		}
+		destination, err := s.parseDestination(ctx, rule.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("targeting rule %d: %w", i+1, err)
+		}
+		parsed, err := core.NormalizeTargetingRule(core.TargetingRule{
+			OS:          rule.Os,
+			Devices:     rule.Devices,
+			Languages:   rule.Languages,
+			Countries:   rule.Countries,
+			Destination: destination,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("targeting rule %d: %w", i+1, err)
+		}
+		out = append(out, parsed)
+	}
+	return out, nil
+}
+
+func targetingToProto(rules []core.TargetingRule) []*proto.TargetingRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	out := make([]*proto.TargetingRule, 0, len(rules))
+	for _, rule := range rules {
+		out = append(out, &proto.TargetingRule{
+			Os:          rule.OS,
+			Devices:     rule.Devices,
+			Languages:   rule.Languages,
+			Countries:   rule.Countries,
+			Destination: rule.Destination,
+		})
+	}
+	return out
+}
+
+// parseVariants checks the variants of a request, and converts them to the stored ones.
+func (s URLShortenerService) parseVariants(ctx context.Context, variants []*proto.Variant) ([]core.Variant, error) {
+	out := make([]core.Variant, 0, len(variants))
+	for i, v := range variants {
+		if strings.TrimSpace(v.Destination) == "" {
+			return nil, fmt.Errorf("variant %d: missing destination", i+1)
+		}
+		destination, err := s.parseDestination(ctx, v.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("variant %d: %w", i+1, err)
+		}
+		out = append(out, core.Variant{Name: v.Name, Destination: destination, Weight: int(v.Weight)})
+	}
+	return core.NormalizeVariants(out)
+}
+
+func variantsToProto(variants []core.Variant) []*proto.Variant {
+	if len(variants) == 0 {
+		return nil
+	}
+	out := make([]*proto.Variant, 0, len(variants))
+	for _, v := range variants {
+		out = append(out, &proto.Variant{Name: v.Name, Destination: v.Destination, Weight: int32(v.Weight)})
+	}
+	return out
+}
+
+// parseDestination checks a destination a link may point to, first against the fixed rules of parseURL,
+// then against the addresses its host resolves to and the destination policy, if any.
+func (s URLShortenerService) parseDestination(ctx context.Context, destination string) (string, error) {
+	return s.parseResolvedDestination(ctx, destination, nil)
+}
+
+// parseResolvedDestination is parseDestination for hosts that may already have been looked up by resolveHosts.
+func (s URLShortenerService) parseResolvedDestination(ctx context.Context, destination string, resolved map[string]error) (string, error) {
+	parsedURL, err := parseURL(destination)
+	if err != nil {
+		return "", err
+	}
+	if s.resolver != nil {
+		hostname := destinationHost(parsedURL)
+		err, ok := resolved[hostname]
+		if !ok {
+			err = s.checkResolved(ctx, hostname)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if s.destinations != nil {
+		if err := s.destinations.Check(parsedURL); err != nil {
+			return "", fmt.Errorf("destination not allowed: %w", err)
+		}
+	}
+	return parsedURL, nil
+}
+
 func parseURL(originalURL string) (string, error) {
 	originalURL = strings.TrimSpace(originalURL)
 	if originalURL == "" {
@@ -139,36 +871,9 @@ func parseURL(originalURL string) (string, error) {
 		return "", fmt.Errorf("potentially unsafe url path")
 	}
 
-	if isLocalhost(parsedURL.Host) {
-		return "", fmt.Errorf("localhost and internal addresses not allowed")
+	if internalHost(parsedURL.Hostname()) {
+		return "", errInternalHost
 	}
 
 	return parsedURL.String(), nil
 }
-
-// isLocalhost checks if a given host string represents a local address.
-// It returns true if the host is "localhost", "127.0.0.1", "::1", or
-// if it's an internal private address (e.g., 10.x.x.x, 192.168.x.x).
-func isLocalhost(host string) bool {
-	// Check for common localhost names and addresses
-	if host == "localhost" || host == "127.0.0.1" || host == "::1" {
-		return true
-	}
-
-	// Remove port from the host string, if present
-	hostWithoutPort, _, err := net.SplitHostPort(host)
-	if err != nil {
-		// If splitting fails, assume the host string is just the hostname/IP
-		hostWithoutPort = host
-	}
-
-	// Parse the IP address
-	ip := net.ParseIP(hostWithoutPort)
-	if ip == nil {
-		// If it's not a valid IP, it can't be a localhost IP
-		return false
-	}
-
-	// Check if the IP is a loopback or private address
-	return ip.IsLoopback() || ip.IsPrivate()
-}
@@ -0,0 +1,329 @@
+package systemtest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuth(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		assert func(t *testing.T)
+	}{
+		{
+			name: "Auth/failure_on_missing_key",
+			assert: func(t *testing.T) {
+				_, err := anonymousClient.ListURLs(ctx, &proto.ListURLsRequest{})
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "Auth/failure_on_unknown_key",
+			assert: func(t *testing.T) {
+				_, err := anonymousClient.ListURLs(withAPIKey(ctx, "usk_unknown"), &proto.ListURLsRequest{})
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "Auth/success_on_health_check_without_key",
+			assert: func(t *testing.T) {
+				res, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{})
+				require.NoError(t, err)
+				require.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+			},
+		},
+		{
+			name: "Auth/success_with_create_scope",
+			assert: func(t *testing.T) {
+				keyCtx := withAPIKey(ctx, mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_CREATE, "").GetKey())
+
+				_, err := anonymousClient.ShortenURL(keyCtx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/create-scope"})
+				require.NoError(t, err)
+
+				_, err = anonymousClient.ListURLs(keyCtx, &proto.ListURLsRequest{})
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+				_, err = anonymousClient.ListAPIKeys(keyCtx, &proto.ListAPIKeysRequest{})
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "Auth/success_with_read_scope",
+			assert: func(t *testing.T) {
+				url := mustShortenURL(t, ctx, "https://example.com/read-scope")
+				keyCtx := withAPIKey(ctx, mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_READ, "").GetKey())
+
+				res, err := anonymousClient.GetOriginalURL(keyCtx, &proto.GetOriginalURLRequest{ShortCode: url.ShortCode})
+				require.NoError(t, err)
+				require.Equal(t, url.LongURL, res.GetOriginalUrl())
+
+				_, err = anonymousClient.ShortenURL(keyCtx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/read-scope"})
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+				_, err = anonymousClient.DisableURL(keyCtx, &proto.DisableURLRequest{ShortCode: url.ShortCode})
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "Auth/success_with_admin_scope",
+			assert: func(t *testing.T) {
+				keyCtx := withAPIKey(ctx, mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_ADMIN, "").GetKey())
+
+				_, err := anonymousClient.ListAPIKeys(keyCtx, &proto.ListAPIKeysRequest{})
+				require.NoError(t, err)
+				_, err = anonymousClient.ShortenURL(keyCtx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/admin-scope"})
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Auth/failure_on_revoked_key",
+			assert: func(t *testing.T) {
+				created := mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_READ, "")
+				_, err := client.RevokeAPIKey(ctx, &proto.RevokeAPIKeyRequest{Id: created.GetApiKey().GetId()})
+				require.NoError(t, err)
+
+				_, err = anonymousClient.ListURLs(withAPIKey(ctx, created.GetKey()), &proto.ListURLsRequest{})
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "Auth/failure_after_too_many_invalid_keys",
+			assert: func(t *testing.T) {
+				validKey := mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_READ, "").GetKey()
+				listURLs := func(key, clientIP string) int {
+					req, err := http.NewRequest(http.MethodGet, httpBaseURL+"/api/v1/urls", nil)
+					require.NoError(t, err)
+					req.Header.Set("Authorization", "Bearer "+key)
+					req.Header.Set("X-Forwarded-For", clientIP)
+					res, err := httpClient.Do(req)
+					require.NoError(t, err)
+					require.NoError(t, res.Body.Close())
+					return res.StatusCode
+				}
+
+				for i := range maxAuthFailures {
+					require.Equal(t, http.StatusUnauthorized, listURLs(fmt.Sprintf("usk_guess-%d", i), "203.0.113.90"))
+				}
+				// Once blocked, keys are no longer looked up, so even a valid one is turned away.
+				require.Equal(t, http.StatusTooManyRequests, listURLs("usk_guess-last", "203.0.113.90"))
+				require.Equal(t, http.StatusTooManyRequests, listURLs(validKey, "203.0.113.90"))
+				// Other clients are not blocked, and forging their address does not unblock the guesser.
+				require.Equal(t, http.StatusOK, listURLs(validKey, "203.0.113.91"))
+				require.Equal(t, http.StatusTooManyRequests, listURLs(validKey, "203.0.113.91, 203.0.113.90"))
+			},
+		},
+		{
+			name: "Auth/success_over_http_with_bearer_header",
+			assert: func(t *testing.T) {
+				req, err := http.NewRequest(http.MethodGet, httpBaseURL+"/api/v1/urls", nil)
+				require.NoError(t, err)
+				res, err := httpClient.Do(req)
+				require.NoError(t, err)
+				require.NoError(t, res.Body.Close())
+				require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+				req.Header.Set("Authorization", "Bearer "+mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_READ, "").GetKey())
+				res, err = httpClient.Do(req)
+				require.NoError(t, err)
+				require.NoError(t, res.Body.Close())
+				require.Equal(t, http.StatusOK, res.StatusCode)
+			},
+		},
+		{
+			name: "CreateAPIKey/failure_on_missing_scope",
+			assert: func(t *testing.T) {
+				_, err := client.CreateAPIKey(ctx, &proto.CreateAPIKeyRequest{Name: "systemtest-" + mustShortCode(t)})
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "CreateAPIKey/failure_on_missing_name",
+			assert: func(t *testing.T) {
+				_, err := client.CreateAPIKey(ctx, &proto.CreateAPIKeyRequest{Scope: proto.APIKeyScope_API_KEY_SCOPE_READ})
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "CreateAPIKey/failure_on_name_taken",
+			assert: func(t *testing.T) {
+				created := mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_READ, "")
+				_, err := client.CreateAPIKey(ctx, &proto.CreateAPIKeyRequest{
+					Name:  created.GetApiKey().GetName(),
+					Scope: proto.APIKeyScope_API_KEY_SCOPE_CREATE,
+				})
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name: "CreateAPIKey/success_reusing_name_of_revoked_key",
+			assert: func(t *testing.T) {
+				created := mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_READ, "")
+				_, err := client.RevokeAPIKey(ctx, &proto.RevokeAPIKeyRequest{Id: created.GetApiKey().GetId()})
+				require.NoError(t, err)
+
+				res, err := client.CreateAPIKey(ctx, &proto.CreateAPIKeyRequest{
+					Name:  created.GetApiKey().GetName(),
+					Scope: proto.APIKeyScope_API_KEY_SCOPE_READ,
+				})
+				require.NoError(t, err)
+				require.NotEqual(t, created.GetKey(), res.GetKey())
+			},
+		},
+		{
+			name: "ListAPIKeys/success",
+			assert: func(t *testing.T) {
+				active := mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_CREATE, "")
+				revoked := mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_READ, "")
+				_, err := client.RevokeAPIKey(ctx, &proto.RevokeAPIKeyRequest{Id: revoked.GetApiKey().GetId()})
+				require.NoError(t, err)
+
+				res, err := client.ListAPIKeys(ctx, &proto.ListAPIKeysRequest{})
+				require.NoError(t, err)
+				byID := make(map[string]*proto.APIKey)
+				for _, key := range res.GetApiKeys() {
+					byID[key.GetId()] = key
+				}
+				require.Contains(t, byID, active.GetApiKey().GetId())
+				require.Equal(t, proto.APIKeyScope_API_KEY_SCOPE_CREATE, byID[active.GetApiKey().GetId()].GetScope())
+				require.Nil(t, byID[active.GetApiKey().GetId()].GetRevokedAt())
+				require.Contains(t, byID, revoked.GetApiKey().GetId())
+				require.NotNil(t, byID[revoked.GetApiKey().GetId()].GetRevokedAt())
+			},
+		},
+		{
+			name: "Ownership/success_managing_own_links",
+			assert: func(t *testing.T) {
+				team := "team-" + mustShortCode(t)
+				creator := withAPIKey(ctx, mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_CREATE, team).GetKey())
+				reader := withAPIKey(ctx, mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_READ, team).GetKey())
+
+				res, err := anonymousClient.ShortenURL(creator, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/owned"})
+				require.NoError(t, err)
+				shortCode := res.GetShortCode()
+
+				list, err := anonymousClient.ListURLs(reader, &proto.ListURLsRequest{})
+				require.NoError(t, err)
+				require.Len(t, list.GetUrls(), 1)
+				require.Equal(t, shortCode, list.GetUrls()[0].GetShortCode())
+				require.Equal(t, team, list.GetUrls()[0].GetOwner())
+
+				_, err = anonymousClient.GetLinkStats(reader, &proto.GetLinkStatsRequest{ShortCode: shortCode})
+				require.NoError(t, err)
+				_, err = anonymousClient.UpdateURL(creator, &proto.UpdateURLRequest{ShortCode: shortCode, OriginalUrl: "https://example.com/owned/v2"})
+				require.NoError(t, err)
+				_, err = anonymousClient.DisableURL(creator, &proto.DisableURLRequest{ShortCode: shortCode})
+				require.NoError(t, err)
+				_, err = anonymousClient.DeleteURL(creator, &proto.DeleteURLRequest{ShortCode: shortCode})
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Ownership/failure_on_links_of_another_owner",
+			assert: func(t *testing.T) {
+				owner := withAPIKey(ctx, mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_CREATE, "").GetKey())
+				otherCreator := withAPIKey(ctx, mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_CREATE, "").GetKey())
+				otherReader := mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_READ, "")
+
+				res, err := anonymousClient.ShortenURL(owner, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/not-yours"})
+				require.NoError(t, err)
+				shortCode := res.GetShortCode()
+
+				_, err = anonymousClient.UpdateURL(otherCreator, &proto.UpdateURLRequest{ShortCode: shortCode, OriginalUrl: "https://example.com/hijacked"})
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+				_, err = anonymousClient.DisableURL(otherCreator, &proto.DisableURLRequest{ShortCode: shortCode})
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+				_, err = anonymousClient.DeleteURL(otherCreator, &proto.DeleteURLRequest{ShortCode: shortCode})
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+				readerCtx := withAPIKey(ctx, otherReader.GetKey())
+				_, err = anonymousClient.GetLinkStats(readerCtx, &proto.GetLinkStatsRequest{ShortCode: shortCode})
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+				list, err := anonymousClient.ListURLs(readerCtx, &proto.ListURLsRequest{})
+				require.NoError(t, err)
+				require.Empty(t, list.GetUrls())
+
+				// The link is unchanged, and still redirects for everyone.
+				original, err := anonymousClient.GetOriginalURL(readerCtx, &proto.GetOriginalURLRequest{ShortCode: shortCode})
+				require.NoError(t, err)
+				require.Equal(t, "https://example.com/not-yours", original.GetOriginalUrl())
+				require.Equal(t, http.StatusFound, mustGet(t, "/"+shortCode).StatusCode)
+			},
+		},
+		{
+			name: "Ownership/failure_listing_another_owner",
+			assert: func(t *testing.T) {
+				reader := withAPIKey(ctx, mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_READ, "").GetKey())
+				_, err := anonymousClient.ListURLs(reader, &proto.ListURLsRequest{Owner: "someone-else"})
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "Ownership/success_for_admin_on_any_owner",
+			assert: func(t *testing.T) {
+				created := mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_CREATE, "")
+				res, err := anonymousClient.ShortenURL(withAPIKey(ctx, created.GetKey()), &proto.ShortenURLRequest{OriginalUrl: "https://example.com/admin-managed"})
+				require.NoError(t, err)
+
+				list, err := client.ListURLs(ctx, &proto.ListURLsRequest{Owner: created.GetApiKey().GetOwner()})
+				require.NoError(t, err)
+				require.Len(t, list.GetUrls(), 1)
+				require.Equal(t, res.GetShortCode(), list.GetUrls()[0].GetShortCode())
+
+				_, err = client.UpdateURL(ctx, &proto.UpdateURLRequest{ShortCode: res.GetShortCode(), OriginalUrl: "https://example.com/admin-managed/v2"})
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Ownership/success_deduplicating_per_owner",
+			assert: func(t *testing.T) {
+				first := withAPIKey(ctx, mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_CREATE, "").GetKey())
+				second := withAPIKey(ctx, mustCreateAPIKey(t, ctx, proto.APIKeyScope_API_KEY_SCOPE_CREATE, "").GetKey())
+				req := &proto.ShortenURLRequest{OriginalUrl: "https://example.com/dedupe-" + mustShortCode(t), Deduplicate: ptr(true)}
+
+				res1, err := anonymousClient.ShortenURL(first, req)
+				require.NoError(t, err)
+				res2, err := anonymousClient.ShortenURL(second, req)
+				require.NoError(t, err)
+				require.False(t, res2.GetReused())
+				require.NotEqual(t, res1.GetShortCode(), res2.GetShortCode())
+
+				res3, err := anonymousClient.ShortenURL(first, req)
+				require.NoError(t, err)
+				require.True(t, res3.GetReused())
+				require.Equal(t, res1.GetShortCode(), res3.GetShortCode())
+			},
+		},
+		{
+			name: "RevokeAPIKey/failure_on_not_found",
+			assert: func(t *testing.T) {
+				_, err := client.RevokeAPIKey(ctx, &proto.RevokeAPIKeyRequest{Id: "nonexistent"})
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.assert)
+	}
+}
+
+// mustCreateAPIKey creates a key with a unique name. An empty owner defaults to that name.
+func mustCreateAPIKey(t *testing.T, ctx context.Context, scope proto.APIKeyScope, owner string) *proto.CreateAPIKeyResponse {
+	t.Helper()
+	res, err := client.CreateAPIKey(ctx, &proto.CreateAPIKeyRequest{
+		Name:  "systemtest-" + mustShortCode(t),
+		Scope: scope,
+		Owner: owner,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.GetKey())
+	return res
+}
@@ -0,0 +1,76 @@
+package systemtest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T) []core.URL
+		assert func(t *testing.T, urls []core.URL)
+	}{
+		{
+			name: "Cache/success_caching_after_lookup",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShortenURL(t, ctx, "https://example.com/cache-lookup")}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				_, err := client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: urls[0].ShortCode})
+				require.NoError(t, err)
+				require.Eventually(t, func() bool {
+					cached, err := redisClient.Get(ctx, urlPrefix+":"+urls[0].ShortCode).Result()
+					return err == nil && len(cached) > 0
+				}, time.Second, 10*time.Millisecond)
+			},
+		},
+		{
+			name: "Cache/success_keeping_load_from_before_update_out",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShortenURL(t, ctx, "https://example.com/cache-before")}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				key := urlPrefix + ":" + urls[0].ShortCode
+				// A lookup reads the version, then the row, and is about to cache it when the URL is updated.
+				version, err := cache.Version(ctx, urls[0].ShortCode)
+				require.NoError(t, err)
+				_, err = client.UpdateURL(ctx, &proto.UpdateURLRequest{ShortCode: urls[0].ShortCode, OriginalUrl: "https://example.com/cache-after"})
+				require.NoError(t, err)
+
+				require.NoError(t, cache.SetURL(ctx, urls[0], version))
+				exists, err := redisClient.Exists(ctx, key).Result()
+				require.NoError(t, err)
+				require.Zero(t, exists)
+
+				res := mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusFound, res.StatusCode)
+				require.Equal(t, "https://example.com/cache-after", res.Header.Get("Location"))
+				require.Eventually(t, func() bool {
+					cached, err := redisClient.Get(ctx, key).Result()
+					return err == nil && len(cached) > 0
+				}, time.Second, 10*time.Millisecond)
+				res = mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, "https://example.com/cache-after", res.Header.Get("Location"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []core.URL
+			if tt.setup != nil {
+				urls = tt.setup(t)
+			}
+			tt.assert(t, urls)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package systemtest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClickLimit(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T) []core.URL
+		assert func(t *testing.T, urls []core.URL)
+	}{
+		{
+			name: "ClickLimit/success_on_one_time_link",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/reset-password?token=abc", MaxClicks: 1})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res := mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusFound, res.StatusCode)
+				require.Equal(t, urls[0].LongURL, res.Header.Get("Location"))
+				require.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+
+				res = mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusGone, res.StatusCode)
+				require.Empty(t, res.Header.Get("Location"))
+
+				// Lookups find the link used up as well.
+				res = mustGet(t, "/"+urls[0].ShortCode+"+")
+				require.Equal(t, http.StatusGone, res.StatusCode)
+				_, err := client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: urls[0].ShortCode})
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "ClickLimit/success_on_concurrent_redirects",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/invite", MaxClicks: 5})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				var (
+					wg       sync.WaitGroup
+					mu       sync.Mutex
+					statuses = make(map[int]int)
+				)
+				for range 50 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						res, err := httpClient.Get(httpBaseURL + "/" + urls[0].ShortCode)
+						if err != nil {
+							return
+						}
+						_ = res.Body.Close()
+						mu.Lock()
+						statuses[res.StatusCode]++
+						mu.Unlock()
+					}()
+				}
+				wg.Wait()
+				require.Equal(t, map[int]int{http.StatusFound: 5, http.StatusGone: 45}, statuses)
+			},
+		},
+		{
+			name: "ClickLimit/success_sharing_counter_in_redis",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/invite-shared", MaxClicks: 3})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				key := urlPrefix + ":clicks:" + urls[0].ShortCode
+				for _, left := range []string{"2", "1"} {
+					res := mustGet(t, "/"+urls[0].ShortCode)
+					require.Equal(t, http.StatusFound, res.StatusCode)
+					counter, err := redisClient.Get(ctx, key).Result()
+					require.NoError(t, err)
+					require.Equal(t, left, counter)
+				}
+
+				// The last click evicts the link and its counter.
+				res := mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusFound, res.StatusCode)
+				exists, err := redisClient.Exists(ctx, key).Result()
+				require.NoError(t, err)
+				require.Zero(t, exists)
+				res = mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusGone, res.StatusCode)
+			},
+		},
+		{
+			name: "ClickLimit/success_turning_away_used_up_counter_without_database",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/invite-counted", MaxClicks: 2})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				// Another replica used up the shared counter.
+				require.NoError(t, redisClient.Set(ctx, urlPrefix+":clicks:"+urls[0].ShortCode, 0, time.Hour).Err())
+
+				res := mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusGone, res.StatusCode)
+				list, err := client.ListURLs(ctx, &proto.ListURLsRequest{DestinationContains: urls[0].LongURL, PageSize: 1})
+				require.NoError(t, err)
+				require.Len(t, list.GetUrls(), 1)
+				require.EqualValues(t, 2, list.GetUrls()[0].GetRemainingClicks())
+			},
+		},
+		{
+			name: "ClickLimit/success_hiding_destination_from_preview",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/reset-password?token=preview", MaxClicks: 1})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				for _, path := range []string{"/" + urls[0].ShortCode + "+", "/" + urls[0].ShortCode + "/preview"} {
+					res, body := mustGetBody(t, path)
+					require.Equal(t, http.StatusOK, res.StatusCode)
+					require.NotContains(t, body, "reset-password")
+					require.Contains(t, body, "limited number of times")
+				}
+
+				// Previews do not use up the link.
+				res := mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusFound, res.StatusCode)
+				require.Equal(t, urls[0].LongURL, res.Header.Get("Location"))
+			},
+		},
+		{
+			name: "ClickLimit/success_counting_lookups_as_clicks",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/reset-password?token=lookup", MaxClicks: 1})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res, err := client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: urls[0].ShortCode})
+				require.NoError(t, err)
+				require.Equal(t, urls[0].LongURL, res.GetOriginalUrl())
+
+				_, err = client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: urls[0].ShortCode})
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+				redirect := mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusGone, redirect.StatusCode)
+			},
+		},
+		{
+			name: "ClickLimit/success_listing_remaining_clicks",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/click-limit-listed", MaxClicks: 3})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res := mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusFound, res.StatusCode)
+
+				list, err := client.ListURLs(ctx, &proto.ListURLsRequest{DestinationContains: urls[0].LongURL, PageSize: 1})
+				require.NoError(t, err)
+				require.Len(t, list.GetUrls(), 1)
+				require.EqualValues(t, 3, list.GetUrls()[0].GetMaxClicks())
+				require.EqualValues(t, 2, list.GetUrls()[0].GetRemainingClicks())
+			},
+		},
+		{
+			name: "ClickLimit/success_not_counting_password_forms",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{
+					OriginalUrl: "https://example.com/one-time-secret",
+					Password:    "hunter2",
+					MaxClicks:   1,
+				})
+				require.NoError(t, err)
+				path := "/" + res.GetShortCode()
+
+				form := mustGet(t, path)
+				require.Equal(t, http.StatusOK, form.StatusCode)
+
+				redirect := mustPostPassword(t, path, "hunter2", "")
+				require.Equal(t, http.StatusSeeOther, redirect.StatusCode)
+
+				redirect = mustPostPassword(t, path, "hunter2", "")
+				require.Equal(t, http.StatusGone, redirect.StatusCode)
+			},
+		},
+		{
+			name: "ClickLimit/failure_on_invalid_max_clicks",
+			assert: func(t *testing.T, _ []core.URL) {
+				for _, maxClicks := range []int32{-1, core.MaxClicks + 1} {
+					_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/", MaxClicks: maxClicks})
+					require.Equal(t, codes.InvalidArgument, status.Code(err), maxClicks)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []core.URL
+			if tt.setup != nil {
+				urls = tt.setup(t)
+			}
+			tt.assert(t, urls)
+		})
+	}
+}
@@ -4,53 +4,192 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ndajr/urlshortener-go/internal/analytics"
+	"github.com/ndajr/urlshortener-go/internal/cachestore"
 	"github.com/ndajr/urlshortener-go/internal/config"
 	"github.com/ndajr/urlshortener-go/internal/datastore"
+	"github.com/ndajr/urlshortener-go/internal/httpserver"
+	"github.com/ndajr/urlshortener-go/internal/policy"
 	"github.com/ndajr/urlshortener-go/internal/rpcserver"
 	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 )
 
 var (
+	// client authenticates every call with the bootstrap admin key.
 	client proto.URLShortenerServiceClient
+	// anonymousClient sends no API key, unless one is added to the call's context.
+	anonymousClient proto.URLShortenerServiceClient
+	healthClient    healthpb.HealthClient
+	// cache is the cache of the server, and redisClient a connection to its Redis, to check what it caches.
+	cache       *cachestore.Cache
+	redisClient *redis.Client
 )
 
 const (
-	dbAddr       = "postgres://ndev@localhost:5432/urlshortener?sslmode=disable"
+	// dbAddrEnv optionally points the tests at a live Postgres instead of the in-memory datastore,
+	// e.g. postgres://ndev@localhost:5432/urlshortener?sslmode=disable.
+	dbAddrEnv = "SYSTEMTEST_DB_ADDRESS"
+	// redisAddrEnv optionally points the tests at a live Redis instead of an in-memory one, e.g. localhost:6379.
+	redisAddrEnv = "SYSTEMTEST_REDIS_ADDRESS"
 	grpcTestAddr = "localhost:50051"
+	httpTestAddr = "localhost:50052"
+	httpBaseURL  = "http://" + httpTestAddr
+	maxBatchSize = 10
+	adminAPIKey  = "usk_systemtest-admin"
+	// maxAuthFailures is the number of invalid API keys allowed per client.
+	maxAuthFailures = 5
+	// maxPasswordAttempts is the number of password attempts allowed per protected link and client.
+	maxPasswordAttempts = 3
+	// maxPasswordFailures is the number of incorrect passwords allowed per protected link, from all clients.
+	maxPasswordFailures = 5
+	// clickFlushInterval is kept short so recorded clicks show up in stats quickly.
+	clickFlushInterval = 50 * time.Millisecond
+	// trustedProxies is the number of X-Forwarded-For entries trusted for the client address. Tests set
+	// the header to stand in for a load balancer, as requests come from localhost.
+	trustedProxies = 1
+	// policyReloadInterval is kept short so changes to the policy file take effect quickly.
+	policyReloadInterval = 50 * time.Millisecond
+	// publicURL is the base URL the server hands out short URLs on, unlike the address the tests reach it on.
+	publicURL = "https://sho.rt"
+	// urlPrefix prefixes the Redis keys of cached URLs.
+	urlPrefix = "url"
+	// rateLimitCapacity is large enough for no test to be limited, so calls only go through the rate limiter.
+	rateLimitCapacity = 100000
 )
 
 func TestMain(m *testing.M) {
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
-	db, err := datastore.NewStore(ctx, logger, config.AppSettings{DBAddress: dbAddr})
+	appCfg := config.AppSettings{DBDriver: datastore.DriverMemory}
+	if addr := os.Getenv(dbAddrEnv); addr != "" {
+		appCfg = config.AppSettings{DBDriver: datastore.DriverPostgres, DBAddress: addr}
+	}
+	db, err := datastore.New(ctx, logger, appCfg)
 	if err != nil {
 		logger.Error("datastore was unable to start", "error", err)
 		os.Exit(1)
 	}
 
-	grpcServer := rpcserver.NewServer(logger, db, nil, nil)
+	var mr *miniredis.Miniredis
+	redisAddr := os.Getenv(redisAddrEnv)
+	if redisAddr == "" {
+		if mr, err = miniredis.Run(); err != nil {
+			logger.Error("failed to start in-memory redis", "error", err)
+			os.Exit(1)
+		}
+		redisAddr = mr.Addr()
+	}
+	cache, err = cachestore.NewCache(ctx, logger, config.Redis{
+		Addr:      redisAddr,
+		UrlPrefix: urlPrefix,
+		UrlTTL:    time.Hour,
+		LocalSize: 100,
+		LocalTTL:  time.Minute,
+	})
+	if err != nil {
+		logger.Error("failed to connect to cache", "error", err)
+		os.Exit(1)
+	}
+	redisClient = redis.NewClient(&redis.Options{Addr: redisAddr})
+
+	policyDir, err := os.MkdirTemp("", "urlshortener-policy")
+	if err != nil {
+		logger.Error("failed to create policy directory", "error", err)
+		os.Exit(1)
+	}
+	policyFile = filepath.Join(policyDir, "policy.yaml")
+	if err := os.WriteFile(policyFile, []byte(basePolicy), 0o600); err != nil {
+		logger.Error("failed to write policy file", "error", err)
+		os.Exit(1)
+	}
+	destinations, err = policy.Load(logger, policyFile, policyReloadInterval)
+	if err != nil {
+		logger.Error("failed to load policy file", "error", err)
+		os.Exit(1)
+	}
+
 	var wg sync.WaitGroup
+	destinations.Run(ctx, &wg)
+	grpcServer := rpcserver.NewServer(logger, db, cache,
+		config.Shortener{MaxBatchSize: maxBatchSize, PasswordMaxAttempts: maxPasswordAttempts, PasswordMaxFailures: maxPasswordFailures, PasswordLockout: time.Minute},
+		destinations,
+		hosts,
+		config.Auth{Enabled: true, BootstrapKey: adminAPIKey, MaxFailures: maxAuthFailures, FailureWindow: time.Minute},
+		&config.RateLimiter{KeyPrefix: "ratelimit:", Capacity: rateLimitCapacity, RefillRate: rateLimitCapacity, RefillPeriod: time.Second},
+		trustedProxies,
+	)
 	if err := grpcServer.Run(ctx, grpcTestAddr, &wg); err != nil {
 		logger.Error("gRPC server failed during test", "error", err)
 		os.Exit(1)
 	}
 
-	conn, err := grpc.NewClient(grpcTestAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	recorder, err := analytics.NewRecorder(logger, db, config.Analytics{
+		BufferSize:    1000,
+		BatchSize:     100,
+		FlushInterval: clickFlushInterval,
+	})
+	if err != nil {
+		logger.Error("failed to create click recorder", "error", err)
+		os.Exit(1)
+	}
+	recorder.Run(ctx, &wg)
+
+	httpServer := httpserver.NewServer(grpcServer, grpcServer.NewGatewayMux(), recorder, countries, trustedProxies, publicURL, logger, []byte("{}"))
+	if err := httpServer.Run(ctx, httpTestAddr, &wg); err != nil {
+		logger.Error("HTTP server failed during test", "error", err)
+		os.Exit(1)
+	}
+
+	conn, err := grpc.NewClient(grpcTestAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(withAPIKey(ctx, adminAPIKey), method, req, reply, cc, opts...)
+		}),
+	)
 	if err != nil {
 		logger.Error("failed to connect to gRPC server", "error", err)
 		os.Exit(1)
 	}
-	defer conn.Close()
 	client = proto.NewURLShortenerServiceClient(conn)
 
+	anonymousConn, err := grpc.NewClient(grpcTestAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		logger.Error("failed to connect to gRPC server", "error", err)
+		os.Exit(1)
+	}
+	anonymousClient = proto.NewURLShortenerServiceClient(anonymousConn)
+	healthClient = healthpb.NewHealthClient(anonymousConn)
+
 	code := m.Run()
+
+	_ = conn.Close()
+	_ = anonymousConn.Close()
+	cancel()
+	wg.Wait()
+	db.Close()
+	cache.Close()
+	_ = redisClient.Close()
+	if mr != nil {
+		mr.Close()
+	}
+	_ = os.RemoveAll(policyDir)
 	os.Exit(code)
 }
+
+// withAPIKey authenticates the calls made with the returned context.
+func withAPIKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+key)
+}
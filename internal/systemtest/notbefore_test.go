@@ -0,0 +1,161 @@
+package systemtest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestNotBefore(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T) []core.URL
+		assert func(t *testing.T, urls []core.URL)
+	}{
+		{
+			name: "NotBefore/success_redirecting_to_placeholder",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/launch", NotBefore: timestamppb.New(time.Now().Add(time.Hour)), PlaceholderUrl: "https://example.com/coming-soon"})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res := mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusFound, res.StatusCode)
+				require.Equal(t, "https://example.com/coming-soon", res.Header.Get("Location"))
+				require.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+			},
+		},
+		{
+			name: "NotBefore/success_serving_not_yet_available",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/keynote", NotBefore: timestamppb.New(time.Now().Add(time.Hour))})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res, body := mustGetBody(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+				require.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+				retryAfter, err := strconv.Atoi(res.Header.Get("Retry-After"))
+				require.NoError(t, err)
+				require.InDelta(t, time.Hour.Seconds(), retryAfter, 10)
+				require.Contains(t, body, "Not yet available")
+				require.NotContains(t, body, urls[0].LongURL)
+			},
+		},
+		{
+			name: "NotBefore/success_going_live",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/drop", NotBefore: timestamppb.New(time.Now().Add(1500 * time.Millisecond)), PlaceholderUrl: "https://example.com/teaser"})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				path := "/" + urls[0].ShortCode
+				require.Equal(t, "https://example.com/teaser", mustGet(t, path).Header.Get("Location"))
+
+				require.Eventually(t, func() bool {
+					return mustGet(t, path).Header.Get("Location") == urls[0].LongURL
+				}, 5*time.Second, 100*time.Millisecond)
+
+				// Only the visits after the link went live are clicks.
+				require.Eventually(t, func() bool {
+					stats, err := client.GetLinkStats(ctx, &proto.GetLinkStatsRequest{ShortCode: urls[0].ShortCode})
+					return err == nil && stats.GetTotalClicks() == 1
+				}, 2*time.Second, clickFlushInterval)
+			},
+		},
+		{
+			name: "NotBefore/failure_looking_up_before_going_live",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/unannounced", NotBefore: timestamppb.New(time.Now().Add(1500 * time.Millisecond))})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res, err := client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: urls[0].ShortCode})
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+				require.Empty(t, res.GetOriginalUrl())
+
+				require.Eventually(t, func() bool {
+					res, err := client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: urls[0].ShortCode})
+					return err == nil && res.GetOriginalUrl() == urls[0].LongURL
+				}, 5*time.Second, 100*time.Millisecond)
+			},
+		},
+		{
+			name: "NotBefore/success_previewing_without_destination",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/secret-product", NotBefore: timestamppb.New(time.Now().Add(time.Hour))})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res, body := mustGetBody(t, "/"+urls[0].ShortCode+"+")
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.Contains(t, body, "goes live on")
+				require.NotContains(t, body, urls[0].LongURL)
+			},
+		},
+		{
+			name: "NotBefore/success_listing_schedule",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/not-before-listed", NotBefore: timestamppb.New(time.Now().Add(time.Hour)), PlaceholderUrl: "https://example.com/not-before-teaser"})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				list, err := client.ListURLs(ctx, &proto.ListURLsRequest{DestinationContains: urls[0].LongURL, PageSize: 1})
+				require.NoError(t, err)
+				require.Len(t, list.GetUrls(), 1)
+				require.WithinDuration(t, *urls[0].NotBefore, list.GetUrls()[0].GetNotBefore().AsTime(), time.Millisecond)
+				require.Equal(t, "https://example.com/not-before-teaser", list.GetUrls()[0].GetPlaceholderUrl())
+			},
+		},
+		{
+			name: "NotBefore/success_not_deduplicated",
+			assert: func(t *testing.T, _ []core.URL) {
+				const originalURL = "https://example.com/not-before-dedup"
+				plain, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL, Deduplicate: new(bool)})
+				require.NoError(t, err)
+
+				dedup := true
+				scheduled, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{
+					OriginalUrl: originalURL,
+					Deduplicate: &dedup,
+					NotBefore:   timestamppb.New(time.Now().Add(time.Hour)),
+				})
+				require.NoError(t, err)
+				require.False(t, scheduled.GetReused())
+				require.NotEqual(t, plain.GetShortCode(), scheduled.GetShortCode())
+			},
+		},
+		{
+			name: "NotBefore/failure_on_invalid_schedule",
+			assert: func(t *testing.T, _ []core.URL) {
+				in := func(d time.Duration) *timestamppb.Timestamp { return timestamppb.New(time.Now().Add(d)) }
+				invalid := map[string]*proto.ShortenURLRequest{
+					"in_the_past":                  {NotBefore: in(-time.Minute)},
+					"after_expiry":                 {NotBefore: in(2 * time.Hour), ExpiresAt: in(time.Hour)},
+					"placeholder_without_schedule": {PlaceholderUrl: "https://example.com/soon"},
+					"unsafe_placeholder":           {NotBefore: in(time.Hour), PlaceholderUrl: "http://127.0.0.1/soon"},
+				}
+				for name, req := range invalid {
+					req.OriginalUrl = "https://example.com/"
+					_, err := client.ShortenURL(ctx, req)
+					require.Equal(t, codes.InvalidArgument, status.Code(err), name)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []core.URL
+			if tt.setup != nil {
+				urls = tt.setup(t)
+			}
+			tt.assert(t, urls)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package systemtest
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	"github.com/ndajr/urlshortener-go/internal/policy"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// basePolicy is the destination policy every test runs with, unless it writes another one.
+const basePolicy = `
+block:
+  - domain: "*.phishing.example.net"
+    reason: known phishing domain
+  - url: '^https?://[^/]+/.*\.exe$'
+    reason: links to executables are not allowed
+`
+
+var (
+	// policyFile is the file destinations is loaded from, and reloaded when it changes.
+	policyFile   string
+	destinations *policy.Engine
+)
+
+func TestDestinationPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T) []core.URL
+		assert func(t *testing.T, urls []core.URL)
+	}{
+		{
+			name: "Policy/failure_on_blocked_domain",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://login.Phishing.example.net/account"})
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				require.Equal(t, "destination not allowed: known phishing domain", status.Convert(err).Message())
+			},
+		},
+		{
+			name: "Policy/failure_on_blocked_url_pattern",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://downloads.example.com/tools/setup.exe"})
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				require.Equal(t, "destination not allowed: links to executables are not allowed", status.Convert(err).Message())
+			},
+		},
+		{
+			name: "Policy/failure_on_every_destination_of_a_link",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShortenURL(t, ctx, "https://example.com/policy-update")}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				const blocked = "https://www.phishing.example.net/"
+				invalid := map[string]*proto.ShortenURLRequest{
+					"targeting_rule": {Targeting: []*proto.TargetingRule{{Os: []string{core.OSIOS}, Destination: blocked}}},
+					"variant": {Variants: []*proto.Variant{
+						{Destination: "https://example.com/a", Weight: 1},
+						{Destination: blocked, Weight: 1},
+					}},
+				}
+				for name, req := range invalid {
+					req.OriginalUrl = "https://example.com/"
+					_, err := client.ShortenURL(ctx, req)
+					require.Equal(t, codes.InvalidArgument, status.Code(err), name)
+					require.Contains(t, status.Convert(err).Message(), "known phishing domain", name)
+				}
+
+				_, err := client.UpdateURL(ctx, &proto.UpdateURLRequest{ShortCode: urls[0].ShortCode, OriginalUrl: blocked})
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+				batch, err := client.BatchShortenURL(ctx, &proto.BatchShortenURLRequest{OriginalUrls: []string{"https://example.com/policy-batch", blocked}})
+				require.NoError(t, err)
+				require.NotEmpty(t, batch.GetResults()[0].GetShortCode())
+				require.Equal(t, "destination not allowed: known phishing domain", batch.GetResults()[1].GetError())
+			},
+		},
+		{
+			name: "Policy/success_blocking_new_domain_without_restart",
+			assert: func(t *testing.T, _ []core.URL) {
+				const originalURL = "https://secure-login.example.org/"
+				_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL})
+				require.NoError(t, err)
+
+				mustWritePolicy(t, basePolicy+`
+  - domain: "secure-login.example.org"
+    reason: reported as phishing
+`)
+				require.Eventually(t, func() bool {
+					_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL})
+					return status.Convert(err).Message() == "destination not allowed: reported as phishing"
+				}, 2*time.Second, policyReloadInterval)
+			},
+		},
+		{
+			name: "Policy/success_limiting_to_allowlist",
+			assert: func(t *testing.T, _ []core.URL) {
+				mustWritePolicy(t, `
+allow:
+  - domain: example.com
+  - domain: "*.example.com"
+`)
+				require.Eventually(t, func() bool {
+					_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.org/"})
+					return status.Convert(err).Message() == "destination not allowed: not on the allowlist"
+				}, 2*time.Second, policyReloadInterval)
+
+				for _, originalURL := range []string{"https://example.com/allowed", "https://docs.example.com/allowed"} {
+					_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL})
+					require.NoError(t, err, originalURL)
+				}
+			},
+		},
+		{
+			name: "Policy/success_keeping_policy_on_invalid_file",
+			assert: func(t *testing.T, _ []core.URL) {
+				mustWritePolicy(t, `
+block:
+  - url: '(unclosed'
+`)
+				require.Error(t, destinations.Reload())
+
+				_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://login.phishing.example.net/"})
+				require.Equal(t, "destination not allowed: known phishing domain", status.Convert(err).Message())
+			},
+		},
+		{
+			name: "Policy/failure_loading_without_reload_interval",
+			assert: func(t *testing.T, _ []core.URL) {
+				for _, reloadInterval := range []time.Duration{0, -time.Second} {
+					_, err := policy.Load(slog.New(slog.DiscardHandler), policyFile, reloadInterval)
+					require.ErrorContains(t, err, "reload interval must be positive", reloadInterval)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []core.URL
+			if tt.setup != nil {
+				urls = tt.setup(t)
+			}
+			tt.assert(t, urls)
+		})
+	}
+}
+
+// mustWritePolicy replaces the policy file, and puts basePolicy back once the test is done.
+func mustWritePolicy(t *testing.T, content string) {
+	t.Helper()
+	replacePolicy(t, content)
+	t.Cleanup(func() {
+		replacePolicy(t, basePolicy)
+		require.NoError(t, destinations.Reload())
+	})
+}
+
+// replacePolicy renames a new file over the policy file, so it is never reloaded half written.
+func replacePolicy(t *testing.T, content string) {
+	t.Helper()
+	tmp := filepath.Join(filepath.Dir(policyFile), "policy.yaml.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte(content), 0o600))
+	require.NoError(t, os.Rename(tmp, policyFile))
+}
@@ -0,0 +1,77 @@
+package systemtest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreview(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T) []core.URL
+		assert func(t *testing.T, urls []core.URL)
+	}{
+		{
+			name: "Preview/success_with_plus_suffix",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShortenURL(t, ctx, "https://example.com/pricing")}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res, body := mustGetBody(t, "/"+urls[0].ShortCode+"+")
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.Empty(t, res.Header.Get("Location"))
+				require.Contains(t, body, urls[0].LongURL)
+				require.Contains(t, body, time.Now().UTC().Format("January 2, 2006"))
+				require.Contains(t, body, `href="/`+urls[0].ShortCode+`"`)
+			},
+		},
+		{
+			name: "Preview/success_with_preview_path",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShortenURL(t, ctx, "https://example.com/careers")}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res, body := mustGetBody(t, "/"+urls[0].ShortCode+"/preview")
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.Contains(t, body, urls[0].LongURL)
+			},
+		},
+		{
+			name: "Preview/success_hiding_protected_destination",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/offer-letter", Password: "hunter2"})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res, body := mustGetBody(t, "/"+urls[0].ShortCode+"+")
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.NotContains(t, body, urls[0].LongURL)
+				require.Contains(t, body, "password protected")
+			},
+		},
+		{
+			name: "Preview/failure_on_not_found",
+			assert: func(t *testing.T, _ []core.URL) {
+				require.Equal(t, http.StatusNotFound, mustGet(t, "/nonexistent-code+").StatusCode)
+				require.Equal(t, http.StatusNotFound, mustGet(t, "/nonexistent-code/preview").StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []core.URL
+			if tt.setup != nil {
+				urls = tt.setup(t)
+			}
+			tt.assert(t, urls)
+		})
+	}
+}
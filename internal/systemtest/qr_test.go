@@ -0,0 +1,140 @@
+package systemtest
+
+import (
+	"context"
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQRCode(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T) []core.URL
+		assert func(t *testing.T, urls []core.URL)
+	}{
+		{
+			name: "QRCode/success_png",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShortenURL(t, ctx, "https://example.com/poster")}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res, err := httpClient.Get(httpBaseURL + "/" + urls[0].ShortCode + "/qr")
+				require.NoError(t, err)
+				defer func() { require.NoError(t, res.Body.Close()) }()
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.Equal(t, "image/png", res.Header.Get("Content-Type"))
+
+				img, err := png.Decode(res.Body)
+				require.NoError(t, err)
+				require.Equal(t, 256, img.Bounds().Dx())
+				require.Equal(t, 256, img.Bounds().Dy())
+
+				// The margin is blank, and the finder pattern in the top left corner starts right after it.
+				isDark := func(x, y int) bool {
+					r, _, _, _ := img.At(x, y).RGBA()
+					return r == 0
+				}
+				require.False(t, isDark(0, 0))
+				var first int
+				for first < 256 && !isDark(first, first) {
+					first++
+				}
+				require.Less(t, first, 128)
+			},
+		},
+		{
+			name: "QRCode/success_svg_with_options",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShortenURL(t, ctx, "https://example.com/flyer")}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res, body := mustGetBody(t, "/"+urls[0].ShortCode+"/qr?format=svg&size=512&margin=0&ecc=H")
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.Equal(t, "image/svg+xml", res.Header.Get("Content-Type"))
+				require.True(t, strings.HasPrefix(body, "<svg"))
+				require.Contains(t, body, `width="512"`)
+				// Without a margin, the finder pattern starts at the origin.
+				require.Contains(t, body, `d="M0 0h7v1h-7z`)
+			},
+		},
+		{
+			name: "QRCode/success_encoding_public_url_whatever_the_host",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShortenURL(t, ctx, "https://example.com/leaflet")}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				get := func(host, proto string) string {
+					req, err := http.NewRequest(http.MethodGet, httpBaseURL+"/"+urls[0].ShortCode+"/qr?format=svg", nil)
+					require.NoError(t, err)
+					if host != "" {
+						req.Host = host
+					}
+					if proto != "" {
+						req.Header.Set("X-Forwarded-Proto", proto)
+					}
+					res, err := httpClient.Do(req)
+					require.NoError(t, err)
+					defer func() { require.NoError(t, res.Body.Close()) }()
+					require.Equal(t, http.StatusOK, res.StatusCode)
+					require.Equal(t, "public, max-age=86400", res.Header.Get("Cache-Control"))
+					body, err := io.ReadAll(res.Body)
+					require.NoError(t, err)
+					return string(body)
+				}
+				// The code encodes the configured public URL, so a forged Host cannot point it elsewhere.
+				require.Equal(t, get("", ""), get("attacker.example", "https"))
+			},
+		},
+		{
+			name: "QRCode/success_on_protected",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/badges", Password: "hunter2"})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res := mustGet(t, "/"+urls[0].ShortCode+"/qr")
+				require.Equal(t, http.StatusOK, res.StatusCode)
+			},
+		},
+		{
+			name: "QRCode/failure_on_not_found",
+			assert: func(t *testing.T, _ []core.URL) {
+				res := mustGet(t, "/nonexistent-code/qr")
+				require.Equal(t, http.StatusNotFound, res.StatusCode)
+			},
+		},
+		{
+			name: "QRCode/failure_on_invalid_options",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShortenURL(t, ctx, "https://example.com/banner")}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				for _, query := range []string{"format=gif", "size=10", "size=big", "margin=-1", "ecc=X"} {
+					res, err := httpClient.Get(httpBaseURL + "/" + urls[0].ShortCode + "/qr?" + query)
+					require.NoError(t, err)
+					_, _ = io.Copy(io.Discard, res.Body)
+					require.NoError(t, res.Body.Close())
+					require.Equal(t, http.StatusBadRequest, res.StatusCode, query)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []core.URL
+			if tt.setup != nil {
+				urls = tt.setup(t)
+			}
+			tt.assert(t, urls)
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package systemtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// httpClient does not follow redirects, so tests can inspect them.
+var httpClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+	Timeout: 5 * time.Second,
+}
+
+func TestRedirect(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T) []core.URL
+		assert func(t *testing.T, urls []core.URL)
+	}{
+		{
+			name: "Redirect/success",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShortenURL(t, ctx, "https://example.com/landing")}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res := mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusFound, res.StatusCode)
+				require.Equal(t, urls[0].LongURL, res.Header.Get("Location"))
+				require.Empty(t, res.Header.Get("Cache-Control"))
+			},
+		},
+		{
+			name: "Redirect/success_with_permanent_status",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/brand", RedirectStatus: http.StatusMovedPermanently})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res := mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusMovedPermanently, res.StatusCode)
+				require.Equal(t, urls[0].LongURL, res.Header.Get("Location"))
+				require.Equal(t, "public, max-age=86400", res.Header.Get("Cache-Control"))
+			},
+		},
+		{
+			name: "Redirect/success_preserving_method",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{
+					mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/webhooks", RedirectStatus: http.StatusTemporaryRedirect}),
+					mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/v2/webhooks", RedirectStatus: http.StatusPermanentRedirect}),
+				}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				for _, link := range urls {
+					res, err := httpClient.Post(httpBaseURL+"/"+link.ShortCode, "application/json", strings.NewReader(`{"event":"ping"}`))
+					require.NoError(t, err)
+					require.NoError(t, res.Body.Close())
+					require.Equal(t, link.RedirectStatus, res.StatusCode)
+					require.Equal(t, link.LongURL, res.Header.Get("Location"))
+				}
+			},
+		},
+		{
+			name: "Redirect/success_on_root_to_docs",
+			assert: func(t *testing.T, _ []core.URL) {
+				res := mustGet(t, "/")
+				require.Equal(t, http.StatusFound, res.StatusCode)
+				require.Equal(t, "/docs/", res.Header.Get("Location"))
+			},
+		},
+		{
+			name: "Redirect/failure_on_not_found",
+			assert: func(t *testing.T, _ []core.URL) {
+				res := mustGet(t, "/nonexistent-code")
+				require.Equal(t, http.StatusNotFound, res.StatusCode)
+			},
+		},
+		{
+			name: "Redirect/failure_on_disabled",
+			setup: func(t *testing.T) []core.URL {
+				url := mustShortenURL(t, ctx, "https://example.com/disabled")
+				_, err := client.DisableURL(ctx, &proto.DisableURLRequest{ShortCode: url.ShortCode})
+				require.NoError(t, err)
+				return []core.URL{url}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res := mustGet(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusGone, res.StatusCode)
+			},
+		},
+		{
+			name: "Redirect/success_dropping_query_by_default",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShortenURL(t, ctx, "https://example.com/sale?ref=site")}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res := mustGet(t, "/"+urls[0].ShortCode+"?utm_source=mail")
+				require.Equal(t, http.StatusFound, res.StatusCode)
+				require.Equal(t, urls[0].LongURL, res.Header.Get("Location"))
+			},
+		},
+		{
+			name: "Redirect/success_passing_query_through",
+			assert: func(t *testing.T, _ []core.URL) {
+				const originalURL = "https://example.com/sale?utm_source=site&ref=a%20b"
+				want := map[proto.QueryPassthrough]string{
+					proto.QueryPassthrough_QUERY_PASSTHROUGH_KEEP:     originalURL + "&campaign=spring",
+					proto.QueryPassthrough_QUERY_PASSTHROUGH_OVERRIDE: "https://example.com/sale?ref=a%20b&campaign=spring&utm_source=mail",
+					proto.QueryPassthrough_QUERY_PASSTHROUGH_APPEND:   originalURL + "&campaign=spring&utm_source=mail",
+				}
+				for mode, location := range want {
+					res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL, QueryPassthrough: mode})
+					require.NoError(t, err)
+
+					redirect := mustGet(t, "/"+res.GetShortCode()+"?utm_source=mail&campaign=spring")
+					require.Equal(t, http.StatusFound, redirect.StatusCode)
+					require.Equal(t, location, redirect.Header.Get("Location"), mode.String())
+				}
+			},
+		},
+		{
+			name: "Redirect/success_on_protected_after_password",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/contracts", Password: "hunter2"})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res, body := mustGetBody(t, "/"+urls[0].ShortCode)
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.Empty(t, res.Header.Get("Location"))
+				require.Contains(t, body, `type="password"`)
+
+				res = mustPostPassword(t, "/"+urls[0].ShortCode, "hunter3", "")
+				require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+				require.Empty(t, res.Header.Get("Location"))
+
+				res = mustPostPassword(t, "/"+urls[0].ShortCode, "hunter2", "")
+				require.Equal(t, http.StatusSeeOther, res.StatusCode)
+				require.Equal(t, urls[0].LongURL, res.Header.Get("Location"))
+			},
+		},
+		{
+			name: "Redirect/failure_on_protected_after_too_many_passwords",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/reviews", Password: "hunter2"})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				path := "/" + urls[0].ShortCode
+				for range maxPasswordAttempts {
+					res := mustPostPassword(t, path, "hunter3", "203.0.113.7")
+					require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+				}
+
+				// Even the correct password is rejected while the client is locked out.
+				res := mustPostPassword(t, path, "hunter2", "203.0.113.7")
+				require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+
+				// Addresses the client adds in front of the one the load balancer saw do not get it a new lockout.
+				res = mustPostPassword(t, path, "hunter2", "198.51.100.77, 203.0.113.7")
+				require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+
+				// Other clients are not affected.
+				res = mustPostPassword(t, path, "hunter2", "203.0.113.8")
+				require.Equal(t, http.StatusSeeOther, res.StatusCode)
+			},
+		},
+		{
+			name: "Redirect/failure_on_protected_after_too_many_passwords_from_many_clients",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/board-minutes", Password: "hunter2"})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				path := "/" + urls[0].ShortCode
+				for i := range maxPasswordFailures {
+					res := mustPostPassword(t, path, "hunter3", fmt.Sprintf("203.0.113.%d", 100+i))
+					require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+				}
+
+				// Guesses spread over many addresses lock the link for every client.
+				res := mustPostPassword(t, path, "hunter2", "203.0.113.99")
+				require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+			},
+		},
+		{
+			name: "Redirect/success_recording_clicks",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShortenURL(t, ctx, "https://example.com/tracked")}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				for range 3 {
+					res := mustGet(t, "/"+urls[0].ShortCode)
+					require.Equal(t, http.StatusFound, res.StatusCode)
+				}
+
+				require.Eventually(t, func() bool {
+					stats, err := client.GetLinkStats(ctx, &proto.GetLinkStatsRequest{ShortCode: urls[0].ShortCode})
+					return err == nil && stats.GetTotalClicks() == 3
+				}, 2*time.Second, clickFlushInterval)
+
+				stats, err := client.GetLinkStats(ctx, &proto.GetLinkStatsRequest{ShortCode: urls[0].ShortCode})
+				require.NoError(t, err)
+				require.Len(t, stats.GetDailyClicks(), 1)
+				require.Equal(t, time.Now().UTC().Format(time.DateOnly), stats.GetDailyClicks()[0].GetDate())
+				require.EqualValues(t, 3, stats.GetDailyClicks()[0].GetClicks())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []core.URL
+			if tt.setup != nil {
+				urls = tt.setup(t)
+			}
+			tt.assert(t, urls)
+		})
+	}
+}
+
+func mustShortenURL(t *testing.T, ctx context.Context, originalURL string) core.URL {
+	t.Helper()
+	return mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL})
+}
+
+// mustShorten shortens a link with the options of req, and returns it with the options the tests assert on.
+func mustShorten(t *testing.T, ctx context.Context, req *proto.ShortenURLRequest) core.URL {
+	t.Helper()
+	res, err := client.ShortenURL(ctx, req)
+	require.NoError(t, err)
+	url := core.URL{
+		ShortCode:      res.GetShortCode(),
+		LongURL:        req.GetOriginalUrl(),
+		RedirectStatus: int(req.GetRedirectStatus()),
+		MaxClicks:      int(req.GetMaxClicks()),
+		PlaceholderURL: req.GetPlaceholderUrl(),
+	}
+	if req.GetNotBefore() != nil {
+		notBefore := req.GetNotBefore().AsTime()
+		url.NotBefore = &notBefore
+	}
+	return url
+}
+
+// mustPostPassword submits the password form of a protected link. The X-Forwarded-For header, if set, is
+// the one the load balancer in front of the server would send, whose last entry is the client address.
+func mustPostPassword(t *testing.T, path, password, forwardedFor string) *http.Response {
+	t.Helper()
+	form := url.Values{"password": {password}}
+	req, err := http.NewRequest(http.MethodPost, httpBaseURL+path, strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	res, err := httpClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	return res
+}
+
+func mustGetBody(t *testing.T, path string) (*http.Response, string) {
+	t.Helper()
+	res, err := httpClient.Get(httpBaseURL + path)
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	return res, string(body)
+}
+
+func mustGet(t *testing.T, path string) *http.Response {
+	t.Helper()
+	res, err := httpClient.Get(httpBaseURL + path)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	return res
+}
@@ -0,0 +1,151 @@
+package systemtest
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// publicAddr is where fakeResolver resolves the host names it does not know, e.g. example.com.
+var publicAddr = netip.MustParseAddr("93.184.215.14")
+
+// hosts stands in for DNS, resolving the host names of the destinations the tests shorten.
+var hosts = fakeResolver{
+	"internal.corp":          {netip.MustParseAddr("10.0.12.7")},
+	"metadata.example.net":   {netip.MustParseAddr("169.254.169.254")},
+	"pods.example.net":       {netip.MustParseAddr("100.64.3.9")},
+	"mapped.example.net":     {netip.MustParseAddr("::ffff:127.0.0.1")},
+	"mixed.example.net":      {publicAddr, netip.MustParseAddr("fd00:ec2::254")},
+	"unresolved.example.net": nil,
+}
+
+type fakeResolver map[string][]netip.Addr
+
+func (f fakeResolver) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	addrs, ok := f[host]
+	if !ok {
+		return []netip.Addr{publicAddr}, nil
+	}
+	if len(addrs) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func TestSSRFProtection(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T) []core.URL
+		assert func(t *testing.T, urls []core.URL)
+	}{
+		{
+			name: "SSRF/failure_on_internal_addresses",
+			assert: func(t *testing.T, _ []core.URL) {
+				for _, originalURL := range []string{
+					"http://localhost/admin",
+					"http://api.localhost/",
+					"http://127.0.0.1/",
+					"http://0x7f000001/",
+					"http://2130706433/",
+					"http://0177.0.0.1/",
+					"http://127.1/",
+					"http://0.0.0.0:8080/",
+					"http://10.1.2.3/",
+					"http://[::1]/",
+					"http://[::ffff:127.0.0.1]/",
+					"http://[::ffff:a00:1]/",
+					"http://[fe80::1%25eth0]/",
+					"http://169.254.169.254/latest/meta-data/",
+					"http://0xa9.0xfe.0xa9.0xfe/latest/meta-data/",
+					"http://100.64.0.1/",
+					"http://LOCALHOST./",
+				} {
+					_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL})
+					require.Equal(t, codes.InvalidArgument, status.Code(err), originalURL)
+					require.Equal(t, "localhost and internal addresses not allowed", status.Convert(err).Message(), originalURL)
+				}
+			},
+		},
+		{
+			name: "SSRF/failure_on_names_resolving_to_internal_addresses",
+			assert: func(t *testing.T, _ []core.URL) {
+				for _, originalURL := range []string{
+					"http://internal.corp/",
+					"http://metadata.example.net/latest/meta-data/",
+					"https://pods.example.net/",
+					"https://mapped.example.net/",
+					"https://Mixed.example.net/",
+				} {
+					_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL})
+					require.Equal(t, codes.InvalidArgument, status.Code(err), originalURL)
+					require.Equal(t, "destination host resolves to an internal address", status.Convert(err).Message(), originalURL)
+				}
+			},
+		},
+		{
+			name: "SSRF/failure_on_unresolved_names",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://unresolved.example.net/"})
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				require.Equal(t, "could not resolve destination host", status.Convert(err).Message())
+			},
+		},
+		{
+			name: "SSRF/failure_on_every_destination_of_a_link",
+			assert: func(t *testing.T, _ []core.URL) {
+				invalid := map[string]*proto.ShortenURLRequest{
+					"targeting_rule": {Targeting: []*proto.TargetingRule{{Os: []string{core.OSIOS}, Destination: "http://internal.corp/"}}},
+					"variant": {Variants: []*proto.Variant{
+						{Destination: "https://example.com/a", Weight: 1},
+						{Destination: "http://2130706433/", Weight: 1},
+					}},
+				}
+				for name, req := range invalid {
+					req.OriginalUrl = "https://example.com/"
+					_, err := client.ShortenURL(ctx, req)
+					require.Equal(t, codes.InvalidArgument, status.Code(err), name)
+				}
+
+				batch, err := client.BatchShortenURL(ctx, &proto.BatchShortenURLRequest{OriginalUrls: []string{"http://internal.corp/", "https://example.com/ssrf-batch"}})
+				require.NoError(t, err)
+				require.Equal(t, "destination host resolves to an internal address", batch.GetResults()[0].GetError())
+				require.NotEmpty(t, batch.GetResults()[1].GetShortCode())
+			},
+		},
+		{
+			name: "SSRF/success_on_public_addresses",
+			assert: func(t *testing.T, _ []core.URL) {
+				for _, originalURL := range []string{
+					"https://example.com/ssrf-public",
+					"http://93.184.215.14/",
+					"http://0x5db8d70e/",
+					"http://[2606:2800:21f:cb07:6820:80da:af6b:8b2c]/",
+					"http://100.128.0.1/",
+					"https://0xdeadbeef.example.com/",
+				} {
+					_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL})
+					require.NoError(t, err, originalURL)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []core.URL
+			if tt.setup != nil {
+				urls = tt.setup(t)
+			}
+			tt.assert(t, urls)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package systemtest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	iPhoneUA  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"
+	iPadUA    = "Mozilla/5.0 (iPad; CPU OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"
+	androidUA = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36"
+	windowsUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+)
+
+// countries stands in for a GeoIP database, resolving the client addresses the tests send with X-Forwarded-For.
+var countries = fakeCountries{
+	"198.51.100.20": "BR",
+	"198.51.100.30": "US",
+}
+
+type fakeCountries map[string]string
+
+func (f fakeCountries) Country(ip net.IP) (string, error) {
+	return f[ip.String()], nil
+}
+
+func TestTargeting(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T) []core.URL
+		assert func(t *testing.T, urls []core.URL)
+	}{
+		{
+			name: "Targeting/success_by_os_and_device",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/app", Targeting: []*proto.TargetingRule{
+					{Os: []string{core.OSIOS}, Devices: []string{core.DeviceTablet}, Destination: "https://example.com/app/ipad"},
+					{Os: []string{core.OSIOS}, Destination: "https://apps.example.com/ios"},
+					{Os: []string{core.OSAndroid}, Destination: "https://apps.example.com/android"},
+				}})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				want := map[string]string{
+					iPadUA:    "https://example.com/app/ipad",
+					iPhoneUA:  "https://apps.example.com/ios",
+					androidUA: "https://apps.example.com/android",
+					windowsUA: urls[0].LongURL,
+					"":        urls[0].LongURL,
+				}
+				for userAgent, location := range want {
+					res := mustGetWithHeaders(t, "/"+urls[0].ShortCode, map[string]string{"User-Agent": userAgent})
+					require.Equal(t, http.StatusFound, res.StatusCode)
+					require.Equal(t, location, res.Header.Get("Location"), userAgent)
+				}
+			},
+		},
+		{
+			name: "Targeting/success_by_language",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/docs", Targeting: []*proto.TargetingRule{
+					{Languages: []string{"pt"}, Destination: "https://example.com/pt/docs"},
+					{Languages: []string{"en-GB"}, Destination: "https://example.co.uk/docs"},
+				}})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				want := map[string]string{
+					"pt-BR,pt;q=0.9,en;q=0.8": "https://example.com/pt/docs",
+					"en-gb":                   "https://example.co.uk/docs",
+					"en;q=0.5, pt-PT":         "https://example.com/pt/docs",
+					"en-US,pt;q=0.5":          urls[0].LongURL,
+					"en":                      urls[0].LongURL,
+				}
+				for acceptLanguage, location := range want {
+					res := mustGetWithHeaders(t, "/"+urls[0].ShortCode, map[string]string{"Accept-Language": acceptLanguage})
+					require.Equal(t, http.StatusFound, res.StatusCode)
+					require.Equal(t, location, res.Header.Get("Location"), acceptLanguage)
+				}
+			},
+		},
+		{
+			name: "Targeting/success_by_country",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/store", Targeting: []*proto.TargetingRule{
+					{Countries: []string{"br", "PT"}, Destination: "https://example.com.br/store"},
+				}})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				want := map[string]string{
+					"198.51.100.20": "https://example.com.br/store",
+					"198.51.100.30": urls[0].LongURL,
+					"198.51.100.40": urls[0].LongURL,
+					// Entries left of the one added by the trusted proxy are sent by the client, so they are ignored.
+					"198.51.100.20, 198.51.100.30": urls[0].LongURL,
+				}
+				for clientIP, location := range want {
+					res := mustGetWithHeaders(t, "/"+urls[0].ShortCode, map[string]string{"X-Forwarded-For": clientIP})
+					require.Equal(t, http.StatusFound, res.StatusCode)
+					require.Equal(t, location, res.Header.Get("Location"), clientIP)
+				}
+			},
+		},
+		{
+			name: "Targeting/success_passing_query_to_rule_destination",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{
+					OriginalUrl:      "https://example.com/signup",
+					QueryPassthrough: proto.QueryPassthrough_QUERY_PASSTHROUGH_KEEP,
+					Targeting:        []*proto.TargetingRule{{Devices: []string{core.DeviceMobile}, Destination: "https://m.example.com/signup?ref=short"}},
+				})
+				require.NoError(t, err)
+
+				redirect := mustGetWithHeaders(t, "/"+res.GetShortCode()+"?ref=mail&utm_source=mail", map[string]string{"User-Agent": androidUA})
+				require.Equal(t, http.StatusFound, redirect.StatusCode)
+				require.Equal(t, "https://m.example.com/signup?ref=short&utm_source=mail", redirect.Header.Get("Location"))
+			},
+		},
+		{
+			name: "Targeting/success_listing_normalized_rules",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/targeting-listed", Targeting: []*proto.TargetingRule{
+					{Languages: []string{"PT-br"}, Countries: []string{"br"}, Destination: "https://example.com.br/"},
+				}})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				list, err := client.ListURLs(ctx, &proto.ListURLsRequest{DestinationContains: "example.com/targeting-listed", PageSize: 1})
+				require.NoError(t, err)
+				require.Len(t, list.GetUrls(), 1)
+				rules := list.GetUrls()[0].GetTargeting()
+				require.Len(t, rules, 1)
+				require.Equal(t, []string{"pt-br"}, rules[0].GetLanguages())
+				require.Equal(t, []string{"BR"}, rules[0].GetCountries())
+				require.Equal(t, "https://example.com.br/", rules[0].GetDestination())
+			},
+		},
+		{
+			name: "Targeting/success_not_deduplicated",
+			assert: func(t *testing.T, _ []core.URL) {
+				const originalURL = "https://example.com/targeting-dedup"
+				plain, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL, Deduplicate: new(bool)})
+				require.NoError(t, err)
+
+				dedup := true
+				targeted, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{
+					OriginalUrl: originalURL,
+					Deduplicate: &dedup,
+					Targeting:   []*proto.TargetingRule{{Os: []string{core.OSIOS}, Destination: "https://apps.example.com/ios"}},
+				})
+				require.NoError(t, err)
+				require.False(t, targeted.GetReused())
+				require.NotEqual(t, plain.GetShortCode(), targeted.GetShortCode())
+			},
+		},
+		{
+			name: "Targeting/failure_on_invalid_rules",
+			assert: func(t *testing.T, _ []core.URL) {
+				invalid := map[string][]*proto.TargetingRule{
+					"no_conditions":       {{Destination: "https://example.com/"}},
+					"missing_destination": {{Os: []string{core.OSIOS}}},
+					"unsafe_destination":  {{Os: []string{core.OSIOS}, Destination: "http://localhost/admin"}},
+					"unknown_os":          {{Os: []string{"symbian"}, Destination: "https://example.com/"}},
+					"unknown_device":      {{Devices: []string{"watch"}, Destination: "https://example.com/"}},
+					"invalid_language":    {{Languages: []string{"english"}, Destination: "https://example.com/"}},
+					"invalid_country":     {{Countries: []string{"USA"}, Destination: "https://example.com/"}},
+				}
+				tooMany := make([]*proto.TargetingRule, core.MaxTargetingRules+1)
+				for i := range tooMany {
+					tooMany[i] = &proto.TargetingRule{Os: []string{core.OSIOS}, Destination: "https://example.com/"}
+				}
+				invalid["too_many_rules"] = tooMany
+
+				for name, rules := range invalid {
+					_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/", Targeting: rules})
+					require.Equal(t, codes.InvalidArgument, status.Code(err), name)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []core.URL
+			if tt.setup != nil {
+				urls = tt.setup(t)
+			}
+			tt.assert(t, urls)
+		})
+	}
+}
+
+func mustGetWithHeaders(t *testing.T, path string, headers map[string]string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, httpBaseURL+path, nil)
+	require.NoError(t, err)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	res, err := httpClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	return res
+}
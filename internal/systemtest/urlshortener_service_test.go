@@ -2,14 +2,17 @@ package systemtest
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ndajr/urlshortener-go/internal/core"
 	proto "github.com/ndajr/urlshortener-go/proto/v1"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestURLShorteningService(t *testing.T) {
@@ -61,6 +64,178 @@ func TestURLShorteningService(t *testing.T) {
 				require.Equal(t, codes.InvalidArgument, st.Code())
 			},
 		},
+		{
+			name: "ShortenURL/success_with_custom_alias",
+			assert: func(t *testing.T, _ []core.URL) {
+				alias := "launch-" + mustShortCode(t)
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/launch", CustomAlias: alias})
+				require.NoError(t, err)
+				require.Equal(t, alias, res.GetShortCode())
+
+				got, err := client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: alias})
+				require.NoError(t, err)
+				require.Equal(t, "https://example.com/launch", got.GetOriginalUrl())
+			},
+		},
+		{
+			name: "ShortenURL/failure_on_taken_custom_alias",
+			assert: func(t *testing.T, _ []core.URL) {
+				alias := "taken-" + mustShortCode(t)
+				_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/first", CustomAlias: alias})
+				require.NoError(t, err)
+
+				_, err = client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/second", CustomAlias: alias})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.AlreadyExists, st.Code())
+			},
+		},
+		{
+			name: "ShortenURL/failure_on_invalid_custom_alias",
+			assert: func(t *testing.T, _ []core.URL) {
+				for _, alias := range []string{"ab", "has space", "slash/alias", "api", strings.Repeat("a", 33)} {
+					_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com", CustomAlias: alias})
+					require.Error(t, err, alias)
+					st, ok := status.FromError(err)
+					require.True(t, ok)
+					require.Equal(t, codes.InvalidArgument, st.Code(), alias)
+				}
+			},
+		},
+		{
+			name: "ShortenURL/success_reusing_deduplicated_url",
+			assert: func(t *testing.T, _ []core.URL) {
+				originalURL := "https://example.com/builds/" + mustShortCode(t)
+				first, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL, Deduplicate: ptr(true)})
+				require.NoError(t, err)
+				require.False(t, first.GetReused())
+
+				second, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL, Deduplicate: ptr(true)})
+				require.NoError(t, err)
+				require.True(t, second.GetReused())
+				require.Equal(t, first.GetShortCode(), second.GetShortCode())
+			},
+		},
+		{
+			name: "ShortenURL/success_without_deduplication",
+			assert: func(t *testing.T, _ []core.URL) {
+				originalURL := "https://example.com/builds/" + mustShortCode(t)
+				first, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL})
+				require.NoError(t, err)
+
+				second, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL, Deduplicate: ptr(false)})
+				require.NoError(t, err)
+				require.False(t, second.GetReused())
+				require.NotEqual(t, first.GetShortCode(), second.GetShortCode())
+			},
+		},
+		{
+			name: "ShortenURL/failure_on_expiry_in_the_past",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{
+					OriginalUrl: "https://example.com/expired",
+					ExpiresAt:   timestamppb.New(time.Now().Add(-time.Minute)),
+				})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "ShortenURL/success_not_reusing_protected_url",
+			assert: func(t *testing.T, _ []core.URL) {
+				originalURL := "https://example.com/payslips/" + mustShortCode(t)
+				protected, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL, Password: "hunter2", Deduplicate: ptr(true)})
+				require.NoError(t, err)
+				require.False(t, protected.GetReused())
+
+				plain, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL, Deduplicate: ptr(true)})
+				require.NoError(t, err)
+				require.False(t, plain.GetReused())
+				require.NotEqual(t, protected.GetShortCode(), plain.GetShortCode())
+			},
+		},
+		{
+			name: "ShortenURL/failure_on_invalid_redirect_status",
+			assert: func(t *testing.T, _ []core.URL) {
+				for _, redirectStatus := range []int32{200, 303, 404} {
+					_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/moved", RedirectStatus: redirectStatus})
+					require.Error(t, err)
+					st, ok := status.FromError(err)
+					require.True(t, ok)
+					require.Equal(t, codes.InvalidArgument, st.Code())
+				}
+			},
+		},
+		{
+			name: "ShortenURL/failure_on_unknown_query_passthrough",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/sale", QueryPassthrough: 42})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "ShortenURL/failure_on_password_too_long",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{
+					OriginalUrl: "https://example.com/contract",
+					Password:    strings.Repeat("a", core.MaxPasswordLength+1),
+				})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "BatchShortenURL/success_with_per_item_errors",
+			assert: func(t *testing.T, _ []core.URL) {
+				originalURLs := []string{"https://example.com/news/1", "not-a-url", "https://example.com/news/2"}
+				res, err := client.BatchShortenURL(ctx, &proto.BatchShortenURLRequest{OriginalUrls: originalURLs})
+				require.NoError(t, err)
+				require.Len(t, res.GetResults(), len(originalURLs))
+
+				require.Empty(t, res.GetResults()[1].GetShortCode())
+				require.NotEmpty(t, res.GetResults()[1].GetError())
+
+				for _, i := range []int{0, 2} {
+					result := res.GetResults()[i]
+					require.Empty(t, result.GetError())
+					got, err := client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: result.GetShortCode()})
+					require.NoError(t, err)
+					require.Equal(t, originalURLs[i], got.GetOriginalUrl())
+				}
+			},
+		},
+		{
+			name: "BatchShortenURL/failure_on_too_many_urls",
+			assert: func(t *testing.T, _ []core.URL) {
+				originalURLs := make([]string, maxBatchSize+1)
+				for i := range originalURLs {
+					originalURLs[i] = fmt.Sprintf("https://example.com/%d", i)
+				}
+				_, err := client.BatchShortenURL(ctx, &proto.BatchShortenURLRequest{OriginalUrls: originalURLs})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "BatchShortenURL/failure_on_empty_request",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.BatchShortenURL(ctx, &proto.BatchShortenURLRequest{})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
 		{
 			name: "GetOriginalURL/success",
 			setup: func(t *testing.T) []core.URL {
@@ -79,6 +254,70 @@ func TestURLShorteningService(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "GetOriginalURL/success_before_expiry",
+			assert: func(t *testing.T, _ []core.URL) {
+				expiresAt := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{
+					OriginalUrl: "https://example.com/campaign",
+					ExpiresAt:   timestamppb.New(expiresAt),
+				})
+				require.NoError(t, err)
+
+				got, err := client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: res.GetShortCode()})
+				require.NoError(t, err)
+				require.Equal(t, "https://example.com/campaign", got.GetOriginalUrl())
+				require.True(t, expiresAt.Equal(got.GetExpiresAt().AsTime()))
+			},
+		},
+		{
+			name: "GetOriginalURL/failure_on_expired",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{
+					OriginalUrl: "https://example.com/one-off",
+					ExpiresAt:   timestamppb.New(time.Now().Add(500 * time.Millisecond)),
+				})
+				require.NoError(t, err)
+
+				time.Sleep(time.Second)
+				_, err = client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: res.GetShortCode()})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.FailedPrecondition, st.Code())
+			},
+		},
+		{
+			name: "GetOriginalURL/success_with_password",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/handbook", Password: "hunter2"})
+				require.NoError(t, err)
+
+				got, err := client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: res.GetShortCode(), Password: "hunter2"})
+				require.NoError(t, err)
+				require.Equal(t, "https://example.com/handbook", got.GetOriginalUrl())
+
+				list, err := client.ListURLs(ctx, &proto.ListURLsRequest{DestinationContains: "example.com/handbook", PageSize: 1})
+				require.NoError(t, err)
+				require.Len(t, list.GetUrls(), 1)
+				require.True(t, list.GetUrls()[0].GetPasswordProtected())
+			},
+		},
+		{
+			name: "GetOriginalURL/failure_on_missing_or_incorrect_password",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/salaries", Password: "hunter2"})
+				require.NoError(t, err)
+
+				for _, password := range []string{"", "hunter3"} {
+					_, err = client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: res.GetShortCode(), Password: password})
+					require.Error(t, err)
+					st, ok := status.FromError(err)
+					require.True(t, ok)
+					require.Equal(t, codes.Unauthenticated, st.Code())
+				}
+			},
+		},
 		{
 			name: "GetOriginalURL/failure_on_not_found",
 			assert: func(t *testing.T, urls []core.URL) {
@@ -99,6 +338,184 @@ func TestURLShorteningService(t *testing.T) {
 				require.Equal(t, codes.InvalidArgument, st.Code())
 			},
 		},
+		{
+			name: "UpdateURL/success",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/old-page"})
+				require.NoError(t, err)
+
+				updated, err := client.UpdateURL(ctx, &proto.UpdateURLRequest{ShortCode: res.GetShortCode(), OriginalUrl: "https://example.com/new-page"})
+				require.NoError(t, err)
+				require.Equal(t, "https://example.com/new-page", updated.GetOriginalUrl())
+
+				got, err := client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: res.GetShortCode()})
+				require.NoError(t, err)
+				require.Equal(t, "https://example.com/new-page", got.GetOriginalUrl())
+			},
+		},
+		{
+			name: "UpdateURL/failure_on_invalid_url",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/page"})
+				require.NoError(t, err)
+
+				_, err = client.UpdateURL(ctx, &proto.UpdateURLRequest{ShortCode: res.GetShortCode(), OriginalUrl: "http://localhost/admin"})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "UpdateURL/failure_on_not_found",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.UpdateURL(ctx, &proto.UpdateURLRequest{ShortCode: "nonexistent-code", OriginalUrl: "https://example.com"})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "DeleteURL/success",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/phishing"})
+				require.NoError(t, err)
+
+				_, err = client.DeleteURL(ctx, &proto.DeleteURLRequest{ShortCode: res.GetShortCode()})
+				require.NoError(t, err)
+
+				_, err = client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: res.GetShortCode()})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "DeleteURL/failure_on_not_found",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.DeleteURL(ctx, &proto.DeleteURLRequest{ShortCode: "nonexistent-code"})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "DisableURL/success",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/suspicious"})
+				require.NoError(t, err)
+
+				_, err = client.DisableURL(ctx, &proto.DisableURLRequest{ShortCode: res.GetShortCode()})
+				require.NoError(t, err)
+				// Disabling twice is a no-op.
+				_, err = client.DisableURL(ctx, &proto.DisableURLRequest{ShortCode: res.GetShortCode()})
+				require.NoError(t, err)
+
+				_, err = client.GetOriginalURL(ctx, &proto.GetOriginalURLRequest{ShortCode: res.GetShortCode()})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.FailedPrecondition, st.Code())
+			},
+		},
+		{
+			name: "DisableURL/failure_on_not_found",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.DisableURL(ctx, &proto.DisableURLRequest{ShortCode: "nonexistent-code"})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "ListURLs/success_with_pagination",
+			setup: func(t *testing.T) []core.URL {
+				marker := mustShortCode(t)
+				var urls []core.URL
+				for i := range 3 {
+					originalURL := fmt.Sprintf("https://example.com/%s/%d", marker, i)
+					res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: originalURL})
+					require.NoError(t, err)
+					urls = append(urls, core.URL{ShortCode: res.GetShortCode(), LongURL: originalURL})
+				}
+				return urls
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				// Destinations are matched ignoring case.
+				contains := strings.ToUpper(strings.TrimSuffix(urls[0].LongURL, "/0"))
+				first, err := client.ListURLs(ctx, &proto.ListURLsRequest{PageSize: 2, DestinationContains: contains})
+				require.NoError(t, err)
+				require.Len(t, first.GetUrls(), 2)
+				require.NotEmpty(t, first.GetNextPageToken())
+
+				second, err := client.ListURLs(ctx, &proto.ListURLsRequest{PageSize: 2, DestinationContains: contains, PageToken: first.GetNextPageToken()})
+				require.NoError(t, err)
+				require.Len(t, second.GetUrls(), 1)
+				require.Empty(t, second.GetNextPageToken())
+
+				var listed []string
+				for _, url := range append(first.GetUrls(), second.GetUrls()...) {
+					require.NotNil(t, url.GetCreatedAt())
+					listed = append(listed, url.GetShortCode())
+				}
+				require.ElementsMatch(t, []string{urls[0].ShortCode, urls[1].ShortCode, urls[2].ShortCode}, listed)
+			},
+		},
+		{
+			name: "ListURLs/success_filtering_by_creation_time",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ListURLs(ctx, &proto.ListURLsRequest{CreatedAfter: timestamppb.New(time.Now().Add(time.Hour))})
+				require.NoError(t, err)
+				require.Empty(t, res.GetUrls())
+				require.Empty(t, res.GetNextPageToken())
+			},
+		},
+		{
+			name: "ListURLs/failure_on_invalid_page_token",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.ListURLs(ctx, &proto.ListURLsRequest{PageToken: "not-a-token"})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "GetLinkStats/success_without_clicks",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/stats"})
+				require.NoError(t, err)
+
+				stats, err := client.GetLinkStats(ctx, &proto.GetLinkStatsRequest{ShortCode: res.GetShortCode()})
+				require.NoError(t, err)
+				require.Zero(t, stats.GetTotalClicks())
+				require.Empty(t, stats.GetDailyClicks())
+			},
+		},
+		{
+			name: "GetLinkStats/failure_on_not_found",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.GetLinkStats(ctx, &proto.GetLinkStatsRequest{ShortCode: "nonexistent-code"})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "GetLinkStats/failure_on_invalid_days",
+			assert: func(t *testing.T, _ []core.URL) {
+				_, err := client.GetLinkStats(ctx, &proto.GetLinkStatsRequest{ShortCode: "any-code", Days: 367})
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -111,3 +528,15 @@ func TestURLShorteningService(t *testing.T) {
 		})
 	}
 }
+
+// mustShortCode returns a random suffix so tests can use unique aliases against a shared database.
+func mustShortCode(t *testing.T) string {
+	t.Helper()
+	code, err := core.GenerateShortCode()
+	require.NoError(t, err)
+	return code
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,238 @@
+package systemtest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ndajr/urlshortener-go/internal/core"
+	proto "github.com/ndajr/urlshortener-go/proto/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestVariants(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T) []core.URL
+		assert func(t *testing.T, urls []core.URL)
+	}{
+		{
+			name: "Variants/success_splitting_by_weight",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/landing-a", Variants: []*proto.Variant{
+					{Destination: "https://example.com/landing-a", Weight: 70},
+					{Destination: "https://example.com/landing-b", Weight: 30},
+				}})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				locations := make(map[string]int)
+				for range 200 {
+					res := mustGet(t, "/"+urls[0].ShortCode)
+					require.Equal(t, http.StatusFound, res.StatusCode)
+					require.Empty(t, res.Cookies())
+					locations[res.Header.Get("Location")]++
+				}
+				require.Len(t, locations, 2)
+				require.InDelta(t, 140, locations["https://example.com/landing-a"], 40)
+				require.InDelta(t, 60, locations["https://example.com/landing-b"], 40)
+			},
+		},
+		{
+			name: "Variants/success_keeping_returning_visitors",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/pricing", StickyVariants: true, Variants: []*proto.Variant{
+					{Name: "control", Destination: "https://example.com/pricing", Weight: 1},
+					{Name: "annual", Destination: "https://example.com/pricing-annual", Weight: 1},
+				}})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				path := "/" + urls[0].ShortCode
+				first := mustGetWithHeaders(t, path, map[string]string{"X-Forwarded-For": "198.51.100.50"})
+				require.Equal(t, http.StatusFound, first.StatusCode)
+				require.Len(t, first.Cookies(), 1)
+				cookie := first.Cookies()[0]
+				require.Contains(t, []string{"control", "annual"}, cookie.Value)
+				require.Equal(t, path, cookie.Path)
+				require.True(t, cookie.HttpOnly)
+
+				// The same client is kept on its variant, even without the cookie.
+				for range 5 {
+					res := mustGetWithHeaders(t, path, map[string]string{"X-Forwarded-For": "198.51.100.50"})
+					require.Equal(t, first.Header.Get("Location"), res.Header.Get("Location"))
+				}
+
+				// So is a visitor coming back with the cookie from other clients.
+				for i := range 20 {
+					res := mustGetWithHeaders(t, path, map[string]string{
+						"X-Forwarded-For": fmt.Sprintf("198.51.100.%d", 60+i),
+						"User-Agent":      fmt.Sprintf("%s Build/%d", windowsUA, i),
+						"Cookie":          cookie.Name + "=" + cookie.Value,
+					})
+					require.Equal(t, first.Header.Get("Location"), res.Header.Get("Location"))
+				}
+
+				// A cookie of an unknown variant is replaced.
+				res := mustGetWithHeaders(t, path, map[string]string{"Cookie": cookie.Name + "=removed"})
+				require.Equal(t, http.StatusFound, res.StatusCode)
+				require.Len(t, res.Cookies(), 1)
+				require.Contains(t, []string{"control", "annual"}, res.Cookies()[0].Value)
+			},
+		},
+		{
+			name: "Variants/success_recording_variants",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/hero-a", Variants: []*proto.Variant{
+					{Destination: "https://example.com/hero-a", Weight: 1},
+					{Destination: "https://example.com/hero-b", Weight: 1},
+				}})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				want := make(map[string]int64)
+				for range 10 {
+					res := mustGet(t, "/"+urls[0].ShortCode)
+					require.Equal(t, http.StatusFound, res.StatusCode)
+					variant := strings.ToUpper(strings.TrimPrefix(res.Header.Get("Location"), "https://example.com/hero-"))
+					want[variant]++
+				}
+
+				require.Eventually(t, func() bool {
+					stats, err := client.GetLinkStats(ctx, &proto.GetLinkStatsRequest{ShortCode: urls[0].ShortCode})
+					return err == nil && stats.GetTotalClicks() == 10
+				}, 2*time.Second, clickFlushInterval)
+
+				stats, err := client.GetLinkStats(ctx, &proto.GetLinkStatsRequest{ShortCode: urls[0].ShortCode})
+				require.NoError(t, err)
+				got := make(map[string]int64)
+				for _, v := range stats.GetVariantClicks() {
+					got[v.GetVariant()] = v.GetClicks()
+				}
+				require.Equal(t, want, got)
+			},
+		},
+		{
+			name: "Variants/success_targeting_before_variants",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{
+					OriginalUrl: "https://example.com/onboarding",
+					Targeting:   []*proto.TargetingRule{{Os: []string{core.OSIOS}, Destination: "https://apps.example.com/ios"}},
+					Variants: []*proto.Variant{
+						{Destination: "https://example.com/onboarding-a", Weight: 1},
+						{Destination: "https://example.com/onboarding-b", Weight: 1},
+					},
+					StickyVariants: true,
+				})
+				require.NoError(t, err)
+
+				redirect := mustGetWithHeaders(t, "/"+res.GetShortCode(), map[string]string{"User-Agent": iPhoneUA})
+				require.Equal(t, http.StatusFound, redirect.StatusCode)
+				require.Equal(t, "https://apps.example.com/ios", redirect.Header.Get("Location"))
+				require.Empty(t, redirect.Cookies())
+
+				redirect = mustGetWithHeaders(t, "/"+res.GetShortCode(), map[string]string{"User-Agent": windowsUA})
+				require.Contains(t, []string{"https://example.com/onboarding-a", "https://example.com/onboarding-b"}, redirect.Header.Get("Location"))
+				require.Len(t, redirect.Cookies(), 1)
+			},
+		},
+		{
+			name: "Variants/success_caching_permanent_redirect_privately",
+			assert: func(t *testing.T, _ []core.URL) {
+				res, err := client.ShortenURL(ctx, &proto.ShortenURLRequest{
+					OriginalUrl:    "https://example.com/rebrand-a",
+					RedirectStatus: http.StatusMovedPermanently,
+					Variants: []*proto.Variant{
+						{Destination: "https://example.com/rebrand-a", Weight: 1},
+						{Destination: "https://example.com/rebrand-b", Weight: 1},
+					},
+				})
+				require.NoError(t, err)
+
+				redirect := mustGet(t, "/"+res.GetShortCode())
+				require.Equal(t, http.StatusMovedPermanently, redirect.StatusCode)
+				require.Equal(t, "private, max-age=86400", redirect.Header.Get("Cache-Control"))
+			},
+		},
+		{
+			name: "Variants/success_previewing_every_variant",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/trial-a", Variants: []*proto.Variant{
+					{Destination: "https://example.com/trial-a", Weight: 1},
+					{Destination: "https://example.com/trial-b", Weight: 1},
+				}})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				res, body := mustGetBody(t, "/"+urls[0].ShortCode+"+")
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.Contains(t, body, "https://example.com/trial-a")
+				require.Contains(t, body, "https://example.com/trial-b")
+				require.Empty(t, res.Cookies())
+			},
+		},
+		{
+			name: "Variants/success_listing_named_variants",
+			setup: func(t *testing.T) []core.URL {
+				return []core.URL{mustShorten(t, ctx, &proto.ShortenURLRequest{OriginalUrl: "https://example.com/variants-listed", StickyVariants: true, Variants: []*proto.Variant{
+					{Destination: "https://example.com/variants-listed", Weight: 3},
+					{Name: "blue", Destination: "https://example.com/variants-listed-blue", Weight: 1},
+				}})}
+			},
+			assert: func(t *testing.T, urls []core.URL) {
+				list, err := client.ListURLs(ctx, &proto.ListURLsRequest{DestinationContains: urls[0].LongURL, PageSize: 1})
+				require.NoError(t, err)
+				require.Len(t, list.GetUrls(), 1)
+				require.True(t, list.GetUrls()[0].GetStickyVariants())
+				variants := list.GetUrls()[0].GetVariants()
+				require.Len(t, variants, 2)
+				require.Equal(t, "A", variants[0].GetName())
+				require.EqualValues(t, 3, variants[0].GetWeight())
+				require.Equal(t, "blue", variants[1].GetName())
+			},
+		},
+		{
+			name: "Variants/failure_on_invalid_variants",
+			assert: func(t *testing.T, _ []core.URL) {
+				invalid := map[string]*proto.ShortenURLRequest{
+					"single_variant": {Variants: []*proto.Variant{{Destination: "https://example.com/a", Weight: 1}}},
+					"zero_weight": {Variants: []*proto.Variant{
+						{Destination: "https://example.com/a", Weight: 1},
+						{Destination: "https://example.com/b"},
+					}},
+					"duplicate_names": {Variants: []*proto.Variant{
+						{Name: "x", Destination: "https://example.com/a", Weight: 1},
+						{Name: "x", Destination: "https://example.com/b", Weight: 1},
+					}},
+					"invalid_name": {Variants: []*proto.Variant{
+						{Name: "new variant", Destination: "https://example.com/a", Weight: 1},
+						{Destination: "https://example.com/b", Weight: 1},
+					}},
+					"unsafe_destination": {Variants: []*proto.Variant{
+						{Destination: "https://example.com/a", Weight: 1},
+						{Destination: "http://127.0.0.1/b", Weight: 1},
+					}},
+					"sticky_without_variants": {StickyVariants: true},
+				}
+				for name, req := range invalid {
+					req.OriginalUrl = "https://example.com/"
+					_, err := client.ShortenURL(ctx, req)
+					require.Equal(t, codes.InvalidArgument, status.Code(err), name)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []core.URL
+			if tt.setup != nil {
+				urls = tt.setup(t)
+			}
+			tt.assert(t, urls)
+		})
+	}
+}
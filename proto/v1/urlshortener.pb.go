@@ -11,6 +11,7 @@ import (
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -23,12 +24,159 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// How the query parameters of each visit are passed on to the original URL.
+type QueryPassthrough int32
+
+const (
+	// Query parameters of visits are dropped.
+	QueryPassthrough_QUERY_PASSTHROUGH_UNSPECIFIED QueryPassthrough = 0
+	// Parameters the original URL already has keep its own values.
+	QueryPassthrough_QUERY_PASSTHROUGH_KEEP QueryPassthrough = 1
+	// Parameters the original URL already has take the visit's values.
+	QueryPassthrough_QUERY_PASSTHROUGH_OVERRIDE QueryPassthrough = 2
+	// Parameters the original URL already has get the visit's values added after its own.
+	QueryPassthrough_QUERY_PASSTHROUGH_APPEND QueryPassthrough = 3
+)
+
+// Enum value maps for QueryPassthrough.
+var (
+	QueryPassthrough_name = map[int32]string{
+		0: "QUERY_PASSTHROUGH_UNSPECIFIED",
+		1: "QUERY_PASSTHROUGH_KEEP",
+		2: "QUERY_PASSTHROUGH_OVERRIDE",
+		3: "QUERY_PASSTHROUGH_APPEND",
+	}
+	QueryPassthrough_value = map[string]int32{
+		"QUERY_PASSTHROUGH_UNSPECIFIED": 0,
+		"QUERY_PASSTHROUGH_KEEP":        1,
+		"QUERY_PASSTHROUGH_OVERRIDE":    2,
+		"QUERY_PASSTHROUGH_APPEND":      3,
+	}
+)
+
+func (x QueryPassthrough) Enum() *QueryPassthrough {
+	p := new(QueryPassthrough)
+	*p = x
+	return p
+}
+
+func (x QueryPassthrough) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QueryPassthrough) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_v1_urlshortener_proto_enumTypes[0].Descriptor()
+}
+
+func (QueryPassthrough) Type() protoreflect.EnumType {
+	return &file_proto_v1_urlshortener_proto_enumTypes[0]
+}
+
+func (x QueryPassthrough) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QueryPassthrough.Descriptor instead.
+func (QueryPassthrough) EnumDescriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{0}
+}
+
+// The operations an API key is allowed to perform.
+type APIKeyScope int32
+
+const (
+	APIKeyScope_API_KEY_SCOPE_UNSPECIFIED APIKeyScope = 0
+	// Can shorten URLs, and change or remove the links of its owner.
+	APIKeyScope_API_KEY_SCOPE_CREATE APIKeyScope = 1
+	// Can only look up short links, and list and report on the links of its owner.
+	APIKeyScope_API_KEY_SCOPE_READ APIKeyScope = 2
+	// Can do everything, including managing links and API keys.
+	APIKeyScope_API_KEY_SCOPE_ADMIN APIKeyScope = 3
+)
+
+// Enum value maps for APIKeyScope.
+var (
+	APIKeyScope_name = map[int32]string{
+		0: "API_KEY_SCOPE_UNSPECIFIED",
+		1: "API_KEY_SCOPE_CREATE",
+		2: "API_KEY_SCOPE_READ",
+		3: "API_KEY_SCOPE_ADMIN",
+	}
+	APIKeyScope_value = map[string]int32{
+		"API_KEY_SCOPE_UNSPECIFIED": 0,
+		"API_KEY_SCOPE_CREATE":      1,
+		"API_KEY_SCOPE_READ":        2,
+		"API_KEY_SCOPE_ADMIN":       3,
+	}
+)
+
+func (x APIKeyScope) Enum() *APIKeyScope {
+	p := new(APIKeyScope)
+	*p = x
+	return p
+}
+
+func (x APIKeyScope) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (APIKeyScope) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_v1_urlshortener_proto_enumTypes[1].Descriptor()
+}
+
+func (APIKeyScope) Type() protoreflect.EnumType {
+	return &file_proto_v1_urlshortener_proto_enumTypes[1]
+}
+
+func (x APIKeyScope) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use APIKeyScope.Descriptor instead.
+func (APIKeyScope) EnumDescriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{1}
+}
+
 type ShortenURLRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// The original URL to shorten. Must be a valid, absolute URL.
-	OriginalUrl   string `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	OriginalUrl string `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	// Optional vanity alias to use as the short code instead of a generated one.
+	// Must be 3 to 32 characters long and contain only letters, digits, '-' or '_'.
+	CustomAlias string `protobuf:"bytes,2,opt,name=custom_alias,json=customAlias,proto3" json:"custom_alias,omitempty"`
+	// Optional point in time after which the link stops redirecting. Must be in the future.
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Whether to return an existing short code if the same URL was already shortened.
+	// Defaults to the server-wide setting. Ignored when custom_alias, expires_at, not_before or password is set.
+	Deduplicate *bool `protobuf:"varint,4,opt,name=deduplicate,proto3,oneof" json:"deduplicate,omitempty"`
+	// Optional password, of at most 72 bytes, that visitors must enter before being redirected.
+	Password string `protobuf:"bytes,5,opt,name=password,proto3" json:"password,omitempty"`
+	// Optional HTTP status of the redirect: 301, 302, 307 or 308. Defaults to 302.
+	// Permanent redirects (301 and 308) may be cached by browsers for a day, skipping click tracking.
+	RedirectStatus int32 `protobuf:"varint,6,opt,name=redirect_status,json=redirectStatus,proto3" json:"redirect_status,omitempty"`
+	// Optional passthrough of the query parameters of each visit, e.g. utm_source, to the original URL.
+	// Off by default.
+	QueryPassthrough QueryPassthrough `protobuf:"varint,7,opt,name=query_passthrough,json=queryPassthrough,proto3,enum=proto.v1.QueryPassthrough" json:"query_passthrough,omitempty"`
+	// Optional rules sending some visitors elsewhere than original_url, e.g. phones to an app store.
+	// They are evaluated in order and the first match wins. At most 20.
+	Targeting []*TargetingRule `protobuf:"bytes,8,rep,name=targeting,proto3" json:"targeting,omitempty"`
+	// Optional destinations to split the visits no targeting rule matches between, e.g. for an A/B test,
+	// instead of sending them to original_url. 2 to 10 variants.
+	Variants []*Variant `protobuf:"bytes,9,rep,name=variants,proto3" json:"variants,omitempty"`
+	// Whether to keep sending returning visitors to the same variant, with a cookie or else a hash of the client.
+	// Without it, each visit picks a variant at random.
+	StickyVariants bool `protobuf:"varint,10,opt,name=sticky_variants,json=stickyVariants,proto3" json:"sticky_variants,omitempty"`
+	// Optional number of redirects after which the link is used up and responds with 410 Gone, e.g. 1 for
+	// a one-time link. At most 1000000. Zero means unlimited. Redirects served from browser caches are not
+	// counted, so click-limited links are never cached.
+	MaxClicks int32 `protobuf:"varint,11,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	// Optional point in time at which the link goes live, e.g. for a launch. Must be in the future, and before
+	// expires_at if both are set. Until then, visits get placeholder_url or a "not yet available" page.
+	NotBefore *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	// Optional URL to redirect visits to before not_before, e.g. a teaser page. Requires not_before.
+	PlaceholderUrl string `protobuf:"bytes,13,opt,name=placeholder_url,json=placeholderUrl,proto3" json:"placeholder_url,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ShortenURLRequest) Reset() {
@@ -68,28 +216,121 @@ func (x *ShortenURLRequest) GetOriginalUrl() string {
 	return ""
 }
 
-type ShortenURLResponse struct {
+func (x *ShortenURLRequest) GetCustomAlias() string {
+	if x != nil {
+		return x.CustomAlias
+	}
+	return ""
+}
+
+func (x *ShortenURLRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *ShortenURLRequest) GetDeduplicate() bool {
+	if x != nil && x.Deduplicate != nil {
+		return *x.Deduplicate
+	}
+	return false
+}
+
+func (x *ShortenURLRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *ShortenURLRequest) GetRedirectStatus() int32 {
+	if x != nil {
+		return x.RedirectStatus
+	}
+	return 0
+}
+
+func (x *ShortenURLRequest) GetQueryPassthrough() QueryPassthrough {
+	if x != nil {
+		return x.QueryPassthrough
+	}
+	return QueryPassthrough_QUERY_PASSTHROUGH_UNSPECIFIED
+}
+
+func (x *ShortenURLRequest) GetTargeting() []*TargetingRule {
+	if x != nil {
+		return x.Targeting
+	}
+	return nil
+}
+
+func (x *ShortenURLRequest) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *ShortenURLRequest) GetStickyVariants() bool {
+	if x != nil {
+		return x.StickyVariants
+	}
+	return false
+}
+
+func (x *ShortenURLRequest) GetMaxClicks() int32 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *ShortenURLRequest) GetNotBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotBefore
+	}
+	return nil
+}
+
+func (x *ShortenURLRequest) GetPlaceholderUrl() string {
+	if x != nil {
+		return x.PlaceholderUrl
+	}
+	return ""
+}
+
+type TargetingRule struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// The generated short code.
-	ShortCode     string `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	// Operating systems of the visitor: android, chromeos, ios, linux, macos or windows.
+	Os []string `protobuf:"bytes,1,rep,name=os,proto3" json:"os,omitempty"`
+	// Device classes of the visitor: desktop, mobile or tablet.
+	Devices []string `protobuf:"bytes,2,rep,name=devices,proto3" json:"devices,omitempty"`
+	// Preferred languages of the visitor, from Accept-Language. A tag also matches its more specific tags,
+	// e.g. "pt" matches "pt-BR".
+	Languages []string `protobuf:"bytes,3,rep,name=languages,proto3" json:"languages,omitempty"`
+	// ISO 3166-1 alpha-2 codes of the visitor's country, e.g. "US". Only matched if the server has a GeoIP database.
+	Countries []string `protobuf:"bytes,4,rep,name=countries,proto3" json:"countries,omitempty"`
+	// Where matching visitors are sent to. Must be a valid, absolute URL.
+	Destination   string `protobuf:"bytes,5,opt,name=destination,proto3" json:"destination,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ShortenURLResponse) Reset() {
-	*x = ShortenURLResponse{}
+func (x *TargetingRule) Reset() {
+	*x = TargetingRule{}
 	mi := &file_proto_v1_urlshortener_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ShortenURLResponse) String() string {
+func (x *TargetingRule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ShortenURLResponse) ProtoMessage() {}
+func (*TargetingRule) ProtoMessage() {}
 
-func (x *ShortenURLResponse) ProtoReflect() protoreflect.Message {
+func (x *TargetingRule) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_v1_urlshortener_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -101,40 +342,73 @@ func (x *ShortenURLResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ShortenURLResponse.ProtoReflect.Descriptor instead.
-func (*ShortenURLResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use TargetingRule.ProtoReflect.Descriptor instead.
+func (*TargetingRule) Descriptor() ([]byte, []int) {
 	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *ShortenURLResponse) GetShortCode() string {
+func (x *TargetingRule) GetOs() []string {
 	if x != nil {
-		return x.ShortCode
+		return x.Os
+	}
+	return nil
+}
+
+func (x *TargetingRule) GetDevices() []string {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+func (x *TargetingRule) GetLanguages() []string {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+func (x *TargetingRule) GetCountries() []string {
+	if x != nil {
+		return x.Countries
+	}
+	return nil
+}
+
+func (x *TargetingRule) GetDestination() string {
+	if x != nil {
+		return x.Destination
 	}
 	return ""
 }
 
-type GetOriginalURLRequest struct {
+type Variant struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// The short code to look up.
-	ShortCode     string `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	// Optional name of the variant, recorded with its clicks. At most 32 letters, digits, '-' or '_'.
+	// Defaults to the variant's position as a letter: A, B, C and so on.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Where the visits of the variant are sent to. Must be a valid, absolute URL.
+	Destination string `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	// The share of visits of the variant, relative to the others, e.g. 70 and 30. From 1 to 10000.
+	Weight        int32 `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetOriginalURLRequest) Reset() {
-	*x = GetOriginalURLRequest{}
+func (x *Variant) Reset() {
+	*x = Variant{}
 	mi := &file_proto_v1_urlshortener_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetOriginalURLRequest) String() string {
+func (x *Variant) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetOriginalURLRequest) ProtoMessage() {}
+func (*Variant) ProtoMessage() {}
 
-func (x *GetOriginalURLRequest) ProtoReflect() protoreflect.Message {
+func (x *Variant) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_v1_urlshortener_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -146,40 +420,56 @@ func (x *GetOriginalURLRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetOriginalURLRequest.ProtoReflect.Descriptor instead.
-func (*GetOriginalURLRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Variant.ProtoReflect.Descriptor instead.
+func (*Variant) Descriptor() ([]byte, []int) {
 	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *GetOriginalURLRequest) GetShortCode() string {
+func (x *Variant) GetName() string {
 	if x != nil {
-		return x.ShortCode
+		return x.Name
 	}
 	return ""
 }
 
-type GetOriginalURLResponse struct {
+func (x *Variant) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *Variant) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type ShortenURLResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// The original long URL.
-	OriginalUrl   string `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	// The generated short code.
+	ShortCode string `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	// True if short_code was reused from an earlier request for the same URL.
+	Reused        bool `protobuf:"varint,2,opt,name=reused,proto3" json:"reused,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetOriginalURLResponse) Reset() {
-	*x = GetOriginalURLResponse{}
+func (x *ShortenURLResponse) Reset() {
+	*x = ShortenURLResponse{}
 	mi := &file_proto_v1_urlshortener_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetOriginalURLResponse) String() string {
+func (x *ShortenURLResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetOriginalURLResponse) ProtoMessage() {}
+func (*ShortenURLResponse) ProtoMessage() {}
 
-func (x *GetOriginalURLResponse) ProtoReflect() protoreflect.Message {
+func (x *ShortenURLResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_v1_urlshortener_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -191,38 +481,1629 @@ func (x *GetOriginalURLResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetOriginalURLResponse.ProtoReflect.Descriptor instead.
-func (*GetOriginalURLResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ShortenURLResponse.ProtoReflect.Descriptor instead.
+func (*ShortenURLResponse) Descriptor() ([]byte, []int) {
 	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *GetOriginalURLResponse) GetOriginalUrl() string {
+func (x *ShortenURLResponse) GetShortCode() string {
 	if x != nil {
-		return x.OriginalUrl
+		return x.ShortCode
 	}
 	return ""
 }
 
-var File_proto_v1_urlshortener_proto protoreflect.FileDescriptor
+func (x *ShortenURLResponse) GetReused() bool {
+	if x != nil {
+		return x.Reused
+	}
+	return false
+}
 
-const file_proto_v1_urlshortener_proto_rawDesc = "" +
-	"\n" +
-	"\x1bproto/v1/urlshortener.proto\x12\bproto.v1\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"6\n" +
-	"\x11ShortenURLRequest\x12!\n" +
-	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl\"3\n" +
-	"\x12ShortenURLResponse\x12\x1d\n" +
-	"\n" +
-	"short_code\x18\x01 \x01(\tR\tshortCode\"6\n" +
-	"\x15GetOriginalURLRequest\x12\x1d\n" +
-	"\n" +
-	"short_code\x18\x01 \x01(\tR\tshortCode\";\n" +
-	"\x16GetOriginalURLResponse\x12!\n" +
-	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl2\xf6\x01\n" +
-	"\x13URLShortenerService\x12c\n" +
+type BatchShortenURLRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The original URLs to shorten. Each must be a valid, absolute URL.
+	OriginalUrls  []string `protobuf:"bytes,1,rep,name=original_urls,json=originalUrls,proto3" json:"original_urls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchShortenURLRequest) Reset() {
+	*x = BatchShortenURLRequest{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchShortenURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchShortenURLRequest) ProtoMessage() {}
+
+func (x *BatchShortenURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchShortenURLRequest.ProtoReflect.Descriptor instead.
+func (*BatchShortenURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchShortenURLRequest) GetOriginalUrls() []string {
+	if x != nil {
+		return x.OriginalUrls
+	}
+	return nil
+}
+
+type BatchShortenURLResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per requested URL, in the same order.
+	Results       []*BatchShortenURLResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchShortenURLResponse) Reset() {
+	*x = BatchShortenURLResponse{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchShortenURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchShortenURLResponse) ProtoMessage() {}
+
+func (x *BatchShortenURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchShortenURLResponse.ProtoReflect.Descriptor instead.
+func (*BatchShortenURLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchShortenURLResponse) GetResults() []*BatchShortenURLResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type BatchShortenURLResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The generated short code. Empty if the URL could not be shortened.
+	ShortCode string `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	// Why the URL could not be shortened. Empty on success.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchShortenURLResult) Reset() {
+	*x = BatchShortenURLResult{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchShortenURLResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchShortenURLResult) ProtoMessage() {}
+
+func (x *BatchShortenURLResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchShortenURLResult.ProtoReflect.Descriptor instead.
+func (*BatchShortenURLResult) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchShortenURLResult) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *BatchShortenURLResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetOriginalURLRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The short code to look up.
+	ShortCode string `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	// The password of a protected link. Repeated incorrect passwords lock the caller out for a while.
+	Password      string `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOriginalURLRequest) Reset() {
+	*x = GetOriginalURLRequest{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOriginalURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOriginalURLRequest) ProtoMessage() {}
+
+func (x *GetOriginalURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOriginalURLRequest.ProtoReflect.Descriptor instead.
+func (*GetOriginalURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetOriginalURLRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *GetOriginalURLRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type GetOriginalURLResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The original long URL.
+	OriginalUrl string `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	// When the link expires. Unset for links that never expire.
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOriginalURLResponse) Reset() {
+	*x = GetOriginalURLResponse{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOriginalURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOriginalURLResponse) ProtoMessage() {}
+
+func (x *GetOriginalURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOriginalURLResponse.ProtoReflect.Descriptor instead.
+func (*GetOriginalURLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetOriginalURLResponse) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *GetOriginalURLResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type ListURLsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Maximum number of links to return. Defaults to 50, at most 500.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// The next_page_token of a previous response, to continue listing from there.
+	// All other filters must stay the same across pages.
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Only include links created at or after this time.
+	CreatedAfter *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	// Only include links created before this time.
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	// Only include links whose destination contains this text, ignoring case.
+	DestinationContains string `protobuf:"bytes,5,opt,name=destination_contains,json=destinationContains,proto3" json:"destination_contains,omitempty"`
+	// Only include links of this owner. Callers without the admin scope only see the links of their own owner.
+	Owner         string `protobuf:"bytes,6,opt,name=owner,proto3" json:"owner,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListURLsRequest) Reset() {
+	*x = ListURLsRequest{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListURLsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListURLsRequest) ProtoMessage() {}
+
+func (x *ListURLsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListURLsRequest.ProtoReflect.Descriptor instead.
+func (*ListURLsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListURLsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListURLsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListURLsRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *ListURLsRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+func (x *ListURLsRequest) GetDestinationContains() string {
+	if x != nil {
+		return x.DestinationContains
+	}
+	return ""
+}
+
+func (x *ListURLsRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+type ListURLsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The links in this page.
+	Urls []*URL `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	// Token for the next page. Empty when there are no more links.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListURLsResponse) Reset() {
+	*x = ListURLsResponse{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListURLsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListURLsResponse) ProtoMessage() {}
+
+func (x *ListURLsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListURLsResponse.ProtoReflect.Descriptor instead.
+func (*ListURLsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListURLsResponse) GetUrls() []*URL {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+func (x *ListURLsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type URL struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The short code of the link.
+	ShortCode string `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	// The original long URL.
+	OriginalUrl string `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	// When the link was created.
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// When the link expires. Unset for links that never expire.
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// When the link was disabled. Unset for active links.
+	DisabledAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=disabled_at,json=disabledAt,proto3" json:"disabled_at,omitempty"`
+	// The owner of the API key the link was created with.
+	Owner string `protobuf:"bytes,6,opt,name=owner,proto3" json:"owner,omitempty"`
+	// Whether visitors must enter a password before being redirected.
+	PasswordProtected bool `protobuf:"varint,7,opt,name=password_protected,json=passwordProtected,proto3" json:"password_protected,omitempty"`
+	// The HTTP status of the redirect: 301, 302, 307 or 308.
+	RedirectStatus int32 `protobuf:"varint,8,opt,name=redirect_status,json=redirectStatus,proto3" json:"redirect_status,omitempty"`
+	// How the query parameters of each visit are passed on to the original URL.
+	QueryPassthrough QueryPassthrough `protobuf:"varint,9,opt,name=query_passthrough,json=queryPassthrough,proto3,enum=proto.v1.QueryPassthrough" json:"query_passthrough,omitempty"`
+	// The rules sending some visitors elsewhere than original_url, in evaluation order.
+	Targeting []*TargetingRule `protobuf:"bytes,10,rep,name=targeting,proto3" json:"targeting,omitempty"`
+	// The destinations the visits no targeting rule matches are split between.
+	Variants []*Variant `protobuf:"bytes,11,rep,name=variants,proto3" json:"variants,omitempty"`
+	// Whether returning visitors are kept on the same variant.
+	StickyVariants bool `protobuf:"varint,12,opt,name=sticky_variants,json=stickyVariants,proto3" json:"sticky_variants,omitempty"`
+	// The number of redirects after which the link is used up. Zero if it is unlimited.
+	MaxClicks int32 `protobuf:"varint,13,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	// The number of redirects the link has left. Unset if it is unlimited.
+	RemainingClicks *int32 `protobuf:"varint,14,opt,name=remaining_clicks,json=remainingClicks,proto3,oneof" json:"remaining_clicks,omitempty"`
+	// When the link goes live. Unset for links that are live from their creation.
+	NotBefore *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	// Where visits are redirected to before not_before. Empty for the "not yet available" page.
+	PlaceholderUrl string `protobuf:"bytes,16,opt,name=placeholder_url,json=placeholderUrl,proto3" json:"placeholder_url,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *URL) Reset() {
+	*x = URL{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *URL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*URL) ProtoMessage() {}
+
+func (x *URL) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use URL.ProtoReflect.Descriptor instead.
+func (*URL) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *URL) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *URL) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *URL) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *URL) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *URL) GetDisabledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DisabledAt
+	}
+	return nil
+}
+
+func (x *URL) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *URL) GetPasswordProtected() bool {
+	if x != nil {
+		return x.PasswordProtected
+	}
+	return false
+}
+
+func (x *URL) GetRedirectStatus() int32 {
+	if x != nil {
+		return x.RedirectStatus
+	}
+	return 0
+}
+
+func (x *URL) GetQueryPassthrough() QueryPassthrough {
+	if x != nil {
+		return x.QueryPassthrough
+	}
+	return QueryPassthrough_QUERY_PASSTHROUGH_UNSPECIFIED
+}
+
+func (x *URL) GetTargeting() []*TargetingRule {
+	if x != nil {
+		return x.Targeting
+	}
+	return nil
+}
+
+func (x *URL) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *URL) GetStickyVariants() bool {
+	if x != nil {
+		return x.StickyVariants
+	}
+	return false
+}
+
+func (x *URL) GetMaxClicks() int32 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *URL) GetRemainingClicks() int32 {
+	if x != nil && x.RemainingClicks != nil {
+		return *x.RemainingClicks
+	}
+	return 0
+}
+
+func (x *URL) GetNotBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotBefore
+	}
+	return nil
+}
+
+func (x *URL) GetPlaceholderUrl() string {
+	if x != nil {
+		return x.PlaceholderUrl
+	}
+	return ""
+}
+
+type GetLinkStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The short code to report on.
+	ShortCode string `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	// Number of most recent days (UTC) to include in the daily breakdown. Defaults to 30, at most 366.
+	Days          int32 `protobuf:"varint,2,opt,name=days,proto3" json:"days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLinkStatsRequest) Reset() {
+	*x = GetLinkStatsRequest{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLinkStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLinkStatsRequest) ProtoMessage() {}
+
+func (x *GetLinkStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLinkStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetLinkStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetLinkStatsRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *GetLinkStatsRequest) GetDays() int32 {
+	if x != nil {
+		return x.Days
+	}
+	return 0
+}
+
+type GetLinkStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Total number of recorded clicks since the link was created.
+	TotalClicks int64 `protobuf:"varint,1,opt,name=total_clicks,json=totalClicks,proto3" json:"total_clicks,omitempty"`
+	// Clicks per day in ascending order. Days without clicks are omitted.
+	DailyClicks []*DailyClicks `protobuf:"bytes,2,rep,name=daily_clicks,json=dailyClicks,proto3" json:"daily_clicks,omitempty"`
+	// Total number of clicks per variant of a split link, ordered by variant name.
+	VariantClicks []*VariantClicks `protobuf:"bytes,3,rep,name=variant_clicks,json=variantClicks,proto3" json:"variant_clicks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLinkStatsResponse) Reset() {
+	*x = GetLinkStatsResponse{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLinkStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLinkStatsResponse) ProtoMessage() {}
+
+func (x *GetLinkStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLinkStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetLinkStatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetLinkStatsResponse) GetTotalClicks() int64 {
+	if x != nil {
+		return x.TotalClicks
+	}
+	return 0
+}
+
+func (x *GetLinkStatsResponse) GetDailyClicks() []*DailyClicks {
+	if x != nil {
+		return x.DailyClicks
+	}
+	return nil
+}
+
+func (x *GetLinkStatsResponse) GetVariantClicks() []*VariantClicks {
+	if x != nil {
+		return x.VariantClicks
+	}
+	return nil
+}
+
+type DailyClicks struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The day in UTC, formatted as YYYY-MM-DD.
+	Date string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	// Number of clicks on that day.
+	Clicks        int64 `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DailyClicks) Reset() {
+	*x = DailyClicks{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DailyClicks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DailyClicks) ProtoMessage() {}
+
+func (x *DailyClicks) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DailyClicks.ProtoReflect.Descriptor instead.
+func (*DailyClicks) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DailyClicks) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *DailyClicks) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+type VariantClicks struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the variant.
+	Variant string `protobuf:"bytes,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	// Number of clicks sent to the variant since the link was created.
+	Clicks        int64 `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VariantClicks) Reset() {
+	*x = VariantClicks{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VariantClicks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VariantClicks) ProtoMessage() {}
+
+func (x *VariantClicks) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VariantClicks.ProtoReflect.Descriptor instead.
+func (*VariantClicks) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *VariantClicks) GetVariant() string {
+	if x != nil {
+		return x.Variant
+	}
+	return ""
+}
+
+func (x *VariantClicks) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+type UpdateURLRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The short code to update.
+	ShortCode string `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	// The new destination. Must be a valid, absolute URL.
+	OriginalUrl   string `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateURLRequest) Reset() {
+	*x = UpdateURLRequest{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateURLRequest) ProtoMessage() {}
+
+func (x *UpdateURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateURLRequest.ProtoReflect.Descriptor instead.
+func (*UpdateURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *UpdateURLRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *UpdateURLRequest) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+type UpdateURLResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The new destination, as stored.
+	OriginalUrl   string `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateURLResponse) Reset() {
+	*x = UpdateURLResponse{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateURLResponse) ProtoMessage() {}
+
+func (x *UpdateURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateURLResponse.ProtoReflect.Descriptor instead.
+func (*UpdateURLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *UpdateURLResponse) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+type DeleteURLRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The short code to delete.
+	ShortCode     string `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteURLRequest) Reset() {
+	*x = DeleteURLRequest{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteURLRequest) ProtoMessage() {}
+
+func (x *DeleteURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteURLRequest.ProtoReflect.Descriptor instead.
+func (*DeleteURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DeleteURLRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+type DeleteURLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteURLResponse) Reset() {
+	*x = DeleteURLResponse{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteURLResponse) ProtoMessage() {}
+
+func (x *DeleteURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteURLResponse.ProtoReflect.Descriptor instead.
+func (*DeleteURLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{19}
+}
+
+type DisableURLRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The short code to disable.
+	ShortCode     string `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisableURLRequest) Reset() {
+	*x = DisableURLRequest{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisableURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableURLRequest) ProtoMessage() {}
+
+func (x *DisableURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisableURLRequest.ProtoReflect.Descriptor instead.
+func (*DisableURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DisableURLRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+type DisableURLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisableURLResponse) Reset() {
+	*x = DisableURLResponse{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisableURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableURLResponse) ProtoMessage() {}
+
+func (x *DisableURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisableURLResponse.ProtoReflect.Descriptor instead.
+func (*DisableURLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{21}
+}
+
+type APIKey struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The public identifier of the key.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// A name describing who uses the key.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// What the key is allowed to do.
+	Scope APIKeyScope `protobuf:"varint,3,opt,name=scope,proto3,enum=proto.v1.APIKeyScope" json:"scope,omitempty"`
+	// When the key was created.
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// When the key was revoked. Unset for active keys.
+	RevokedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	// The team or client owning the links created with the key.
+	Owner         string `protobuf:"bytes,6,opt,name=owner,proto3" json:"owner,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *APIKey) Reset() {
+	*x = APIKey{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *APIKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIKey) ProtoMessage() {}
+
+func (x *APIKey) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIKey.ProtoReflect.Descriptor instead.
+func (*APIKey) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *APIKey) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *APIKey) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *APIKey) GetScope() APIKeyScope {
+	if x != nil {
+		return x.Scope
+	}
+	return APIKeyScope_API_KEY_SCOPE_UNSPECIFIED
+}
+
+func (x *APIKey) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *APIKey) GetRevokedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return nil
+}
+
+func (x *APIKey) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+type CreateAPIKeyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A name describing who uses the key. Must be unique among active keys.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// What the key is allowed to do.
+	Scope APIKeyScope `protobuf:"varint,2,opt,name=scope,proto3,enum=proto.v1.APIKeyScope" json:"scope,omitempty"`
+	// The team or client owning the links created with the key. Keys with the same owner can manage each
+	// other's links. Defaults to the name.
+	Owner         string `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyRequest) Reset() {
+	*x = CreateAPIKeyRequest{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyRequest) ProtoMessage() {}
+
+func (x *CreateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CreateAPIKeyRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyRequest) GetScope() APIKeyScope {
+	if x != nil {
+		return x.Scope
+	}
+	return APIKeyScope_API_KEY_SCOPE_UNSPECIFIED
+}
+
+func (x *CreateAPIKeyRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+type CreateAPIKeyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The created key.
+	ApiKey *APIKey `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	// The secret value of the key, to send as "Authorization: Bearer <key>". It cannot be retrieved again.
+	Key           string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyResponse) Reset() {
+	*x = CreateAPIKeyResponse{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyResponse) ProtoMessage() {}
+
+func (x *CreateAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CreateAPIKeyResponse) GetApiKey() *APIKey {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+func (x *CreateAPIKeyResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type ListAPIKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysRequest) Reset() {
+	*x = ListAPIKeysRequest{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAPIKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysRequest) ProtoMessage() {}
+
+func (x *ListAPIKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{25}
+}
+
+type ListAPIKeysResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The API keys, oldest first.
+	ApiKeys       []*APIKey `protobuf:"bytes,1,rep,name=api_keys,json=apiKeys,proto3" json:"api_keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysResponse) Reset() {
+	*x = ListAPIKeysResponse{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAPIKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysResponse) ProtoMessage() {}
+
+func (x *ListAPIKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListAPIKeysResponse) GetApiKeys() []*APIKey {
+	if x != nil {
+		return x.ApiKeys
+	}
+	return nil
+}
+
+type RevokeAPIKeyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The id of the key to revoke.
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyRequest) Reset() {
+	*x = RevokeAPIKeyRequest{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAPIKeyRequest) ProtoMessage() {}
+
+func (x *RevokeAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RevokeAPIKeyRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RevokeAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyResponse) Reset() {
+	*x = RevokeAPIKeyResponse{}
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAPIKeyResponse) ProtoMessage() {}
+
+func (x *RevokeAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_urlshortener_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_urlshortener_proto_rawDescGZIP(), []int{28}
+}
+
+var File_proto_v1_urlshortener_proto protoreflect.FileDescriptor
+
+const file_proto_v1_urlshortener_proto_rawDesc = "" +
+	"\n" +
+	"\x1bproto/v1/urlshortener.proto\x12\bproto.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"\xeb\x04\n" +
+	"\x11ShortenURLRequest\x12!\n" +
+	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl\x12!\n" +
+	"\fcustom_alias\x18\x02 \x01(\tR\vcustomAlias\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12%\n" +
+	"\vdeduplicate\x18\x04 \x01(\bH\x00R\vdeduplicate\x88\x01\x01\x12\x1a\n" +
+	"\bpassword\x18\x05 \x01(\tR\bpassword\x12'\n" +
+	"\x0fredirect_status\x18\x06 \x01(\x05R\x0eredirectStatus\x12G\n" +
+	"\x11query_passthrough\x18\a \x01(\x0e2\x1a.proto.v1.QueryPassthroughR\x10queryPassthrough\x125\n" +
+	"\ttargeting\x18\b \x03(\v2\x17.proto.v1.TargetingRuleR\ttargeting\x12-\n" +
+	"\bvariants\x18\t \x03(\v2\x11.proto.v1.VariantR\bvariants\x12'\n" +
+	"\x0fsticky_variants\x18\n" +
+	" \x01(\bR\x0estickyVariants\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\v \x01(\x05R\tmaxClicks\x129\n" +
+	"\n" +
+	"not_before\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tnotBefore\x12'\n" +
+	"\x0fplaceholder_url\x18\r \x01(\tR\x0eplaceholderUrlB\x0e\n" +
+	"\f_deduplicate\"\x97\x01\n" +
+	"\rTargetingRule\x12\x0e\n" +
+	"\x02os\x18\x01 \x03(\tR\x02os\x12\x18\n" +
+	"\adevices\x18\x02 \x03(\tR\adevices\x12\x1c\n" +
+	"\tlanguages\x18\x03 \x03(\tR\tlanguages\x12\x1c\n" +
+	"\tcountries\x18\x04 \x03(\tR\tcountries\x12 \n" +
+	"\vdestination\x18\x05 \x01(\tR\vdestination\"W\n" +
+	"\aVariant\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdestination\x18\x02 \x01(\tR\vdestination\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x05R\x06weight\"K\n" +
+	"\x12ShortenURLResponse\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x16\n" +
+	"\x06reused\x18\x02 \x01(\bR\x06reused\"=\n" +
+	"\x16BatchShortenURLRequest\x12#\n" +
+	"\roriginal_urls\x18\x01 \x03(\tR\foriginalUrls\"T\n" +
+	"\x17BatchShortenURLResponse\x129\n" +
+	"\aresults\x18\x01 \x03(\v2\x1f.proto.v1.BatchShortenURLResultR\aresults\"L\n" +
+	"\x15BatchShortenURLResult\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"R\n" +
+	"\x15GetOriginalURLRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"v\n" +
+	"\x16GetOriginalURLResponse\x12!\n" +
+	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl\x129\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\x9a\x02\n" +
+	"\x0fListURLsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12?\n" +
+	"\rcreated_after\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\x121\n" +
+	"\x14destination_contains\x18\x05 \x01(\tR\x13destinationContains\x12\x14\n" +
+	"\x05owner\x18\x06 \x01(\tR\x05owner\"]\n" +
+	"\x10ListURLsResponse\x12!\n" +
+	"\x04urls\x18\x01 \x03(\v2\r.proto.v1.URLR\x04urls\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x88\x06\n" +
+	"\x03URL\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12!\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12;\n" +
+	"\vdisabled_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"disabledAt\x12\x14\n" +
+	"\x05owner\x18\x06 \x01(\tR\x05owner\x12-\n" +
+	"\x12password_protected\x18\a \x01(\bR\x11passwordProtected\x12'\n" +
+	"\x0fredirect_status\x18\b \x01(\x05R\x0eredirectStatus\x12G\n" +
+	"\x11query_passthrough\x18\t \x01(\x0e2\x1a.proto.v1.QueryPassthroughR\x10queryPassthrough\x125\n" +
+	"\ttargeting\x18\n" +
+	" \x03(\v2\x17.proto.v1.TargetingRuleR\ttargeting\x12-\n" +
+	"\bvariants\x18\v \x03(\v2\x11.proto.v1.VariantR\bvariants\x12'\n" +
+	"\x0fsticky_variants\x18\f \x01(\bR\x0estickyVariants\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\r \x01(\x05R\tmaxClicks\x12.\n" +
+	"\x10remaining_clicks\x18\x0e \x01(\x05H\x00R\x0fremainingClicks\x88\x01\x01\x129\n" +
+	"\n" +
+	"not_before\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\tnotBefore\x12'\n" +
+	"\x0fplaceholder_url\x18\x10 \x01(\tR\x0eplaceholderUrlB\x13\n" +
+	"\x11_remaining_clicks\"H\n" +
+	"\x13GetLinkStatsRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x12\n" +
+	"\x04days\x18\x02 \x01(\x05R\x04days\"\xb3\x01\n" +
+	"\x14GetLinkStatsResponse\x12!\n" +
+	"\ftotal_clicks\x18\x01 \x01(\x03R\vtotalClicks\x128\n" +
+	"\fdaily_clicks\x18\x02 \x03(\v2\x15.proto.v1.DailyClicksR\vdailyClicks\x12>\n" +
+	"\x0evariant_clicks\x18\x03 \x03(\v2\x17.proto.v1.VariantClicksR\rvariantClicks\"9\n" +
+	"\vDailyClicks\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x16\n" +
+	"\x06clicks\x18\x02 \x01(\x03R\x06clicks\"A\n" +
+	"\rVariantClicks\x12\x18\n" +
+	"\avariant\x18\x01 \x01(\tR\avariant\x12\x16\n" +
+	"\x06clicks\x18\x02 \x01(\x03R\x06clicks\"T\n" +
+	"\x10UpdateURLRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12!\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\"6\n" +
+	"\x11UpdateURLResponse\x12!\n" +
+	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl\"1\n" +
+	"\x10DeleteURLRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\"\x13\n" +
+	"\x11DeleteURLResponse\"2\n" +
+	"\x11DisableURLRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\"\x14\n" +
+	"\x12DisableURLResponse\"\xe5\x01\n" +
+	"\x06APIKey\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12+\n" +
+	"\x05scope\x18\x03 \x01(\x0e2\x15.proto.v1.APIKeyScopeR\x05scope\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"revoked_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\trevokedAt\x12\x14\n" +
+	"\x05owner\x18\x06 \x01(\tR\x05owner\"l\n" +
+	"\x13CreateAPIKeyRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12+\n" +
+	"\x05scope\x18\x02 \x01(\x0e2\x15.proto.v1.APIKeyScopeR\x05scope\x12\x14\n" +
+	"\x05owner\x18\x03 \x01(\tR\x05owner\"S\n" +
+	"\x14CreateAPIKeyResponse\x12)\n" +
+	"\aapi_key\x18\x01 \x01(\v2\x10.proto.v1.APIKeyR\x06apiKey\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\"\x14\n" +
+	"\x12ListAPIKeysRequest\"B\n" +
+	"\x13ListAPIKeysResponse\x12+\n" +
+	"\bapi_keys\x18\x01 \x03(\v2\x10.proto.v1.APIKeyR\aapiKeys\"%\n" +
+	"\x13RevokeAPIKeyRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x16\n" +
+	"\x14RevokeAPIKeyResponse*\x8f\x01\n" +
+	"\x10QueryPassthrough\x12!\n" +
+	"\x1dQUERY_PASSTHROUGH_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16QUERY_PASSTHROUGH_KEEP\x10\x01\x12\x1e\n" +
+	"\x1aQUERY_PASSTHROUGH_OVERRIDE\x10\x02\x12\x1c\n" +
+	"\x18QUERY_PASSTHROUGH_APPEND\x10\x03*w\n" +
+	"\vAPIKeyScope\x12\x1d\n" +
+	"\x19API_KEY_SCOPE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14API_KEY_SCOPE_CREATE\x10\x01\x12\x16\n" +
+	"\x12API_KEY_SCOPE_READ\x10\x02\x12\x17\n" +
+	"\x13API_KEY_SCOPE_ADMIN\x10\x032\xd2\t\n" +
+	"\x13URLShortenerService\x12c\n" +
+	"\n" +
+	"ShortenURL\x12\x1b.proto.v1.ShortenURLRequest\x1a\x1c.proto.v1.ShortenURLResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/api/v1/shorten\x12x\n" +
+	"\x0fBatchShortenURL\x12 .proto.v1.BatchShortenURLRequest\x1a!.proto.v1.BatchShortenURLResponse\" \x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/api/v1/shorten/batch\x12z\n" +
+	"\x0eGetOriginalURL\x12\x1f.proto.v1.GetOriginalURLRequest\x1a .proto.v1.GetOriginalURLResponse\"%\x82\xd3\xe4\x93\x02\x1f\x12\x1d/api/v1/original/{short_code}\x12W\n" +
+	"\bListURLs\x12\x19.proto.v1.ListURLsRequest\x1a\x1a.proto.v1.ListURLsResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/api/v1/urls\x12q\n" +
+	"\fGetLinkStats\x12\x1d.proto.v1.GetLinkStatsRequest\x1a\x1e.proto.v1.GetLinkStatsResponse\"\"\x82\xd3\xe4\x93\x02\x1c\x12\x1a/api/v1/stats/{short_code}\x12j\n" +
+	"\tUpdateURL\x12\x1a.proto.v1.UpdateURLRequest\x1a\x1b.proto.v1.UpdateURLResponse\"$\x82\xd3\xe4\x93\x02\x1e:\x01*2\x19/api/v1/urls/{short_code}\x12g\n" +
+	"\tDeleteURL\x12\x1a.proto.v1.DeleteURLRequest\x1a\x1b.proto.v1.DeleteURLResponse\"!\x82\xd3\xe4\x93\x02\x1b*\x19/api/v1/urls/{short_code}\x12r\n" +
+	"\n" +
+	"DisableURL\x12\x1b.proto.v1.DisableURLRequest\x1a\x1c.proto.v1.DisableURLResponse\")\x82\xd3\xe4\x93\x02#\"!/api/v1/urls/{short_code}/disable\x12l\n" +
+	"\fCreateAPIKey\x12\x1d.proto.v1.CreateAPIKeyRequest\x1a\x1e.proto.v1.CreateAPIKeyResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/api/v1/admin/keys\x12f\n" +
+	"\vListAPIKeys\x12\x1c.proto.v1.ListAPIKeysRequest\x1a\x1d.proto.v1.ListAPIKeysResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/api/v1/admin/keys\x12u\n" +
+	"\fRevokeAPIKey\x12\x1d.proto.v1.RevokeAPIKeyRequest\x1a\x1e.proto.v1.RevokeAPIKeyResponse\"&\x82\xd3\xe4\x93\x02 \"\x1e/api/v1/admin/keys/{id}/revokeB\xee\x01\x92A\xbf\x01\x129\n" +
+	"\x11URL Shortener API\x12\x1dA simple API to shorten URLs.2\x051.0.0*\x02\x01\x022\x10application/json:\x10application/jsonZH\n" +
+	"F\n" +
+	"\n" +
+	"ApiKeyAuth\x128\b\x02\x12#An API key, sent as \"Bearer <key>\".\x1a\rAuthorization \x02b\x10\n" +
+	"\x0e\n" +
 	"\n" +
-	"ShortenURL\x12\x1b.proto.v1.ShortenURLRequest\x1a\x1c.proto.v1.ShortenURLResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/api/v1/shorten\x12z\n" +
-	"\x0eGetOriginalURL\x12\x1f.proto.v1.GetOriginalURLRequest\x1a .proto.v1.GetOriginalURLResponse\"%\x82\xd3\xe4\x93\x02\x1f\x12\x1d/api/v1/original/{short_code}B\x91\x01\x92Ac\x129\n" +
-	"\x11URL Shortener API\x12\x1dA simple API to shorten URLs.2\x051.0.0*\x02\x01\x022\x10application/json:\x10application/jsonZ)github.com/ndajr/urlshortener-go/proto/v1b\x06proto3"
+	"ApiKeyAuth\x12\x00Z)github.com/ndajr/urlshortener-go/proto/v1b\x06proto3"
 
 var (
 	file_proto_v1_urlshortener_proto_rawDescOnce sync.Once
@@ -236,23 +2117,95 @@ func file_proto_v1_urlshortener_proto_rawDescGZIP() []byte {
 	return file_proto_v1_urlshortener_proto_rawDescData
 }
 
-var file_proto_v1_urlshortener_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_v1_urlshortener_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_v1_urlshortener_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
 var file_proto_v1_urlshortener_proto_goTypes = []any{
-	(*ShortenURLRequest)(nil),      // 0: proto.v1.ShortenURLRequest
-	(*ShortenURLResponse)(nil),     // 1: proto.v1.ShortenURLResponse
-	(*GetOriginalURLRequest)(nil),  // 2: proto.v1.GetOriginalURLRequest
-	(*GetOriginalURLResponse)(nil), // 3: proto.v1.GetOriginalURLResponse
+	(QueryPassthrough)(0),           // 0: proto.v1.QueryPassthrough
+	(APIKeyScope)(0),                // 1: proto.v1.APIKeyScope
+	(*ShortenURLRequest)(nil),       // 2: proto.v1.ShortenURLRequest
+	(*TargetingRule)(nil),           // 3: proto.v1.TargetingRule
+	(*Variant)(nil),                 // 4: proto.v1.Variant
+	(*ShortenURLResponse)(nil),      // 5: proto.v1.ShortenURLResponse
+	(*BatchShortenURLRequest)(nil),  // 6: proto.v1.BatchShortenURLRequest
+	(*BatchShortenURLResponse)(nil), // 7: proto.v1.BatchShortenURLResponse
+	(*BatchShortenURLResult)(nil),   // 8: proto.v1.BatchShortenURLResult
+	(*GetOriginalURLRequest)(nil),   // 9: proto.v1.GetOriginalURLRequest
+	(*GetOriginalURLResponse)(nil),  // 10: proto.v1.GetOriginalURLResponse
+	(*ListURLsRequest)(nil),         // 11: proto.v1.ListURLsRequest
+	(*ListURLsResponse)(nil),        // 12: proto.v1.ListURLsResponse
+	(*URL)(nil),                     // 13: proto.v1.URL
+	(*GetLinkStatsRequest)(nil),     // 14: proto.v1.GetLinkStatsRequest
+	(*GetLinkStatsResponse)(nil),    // 15: proto.v1.GetLinkStatsResponse
+	(*DailyClicks)(nil),             // 16: proto.v1.DailyClicks
+	(*VariantClicks)(nil),           // 17: proto.v1.VariantClicks
+	(*UpdateURLRequest)(nil),        // 18: proto.v1.UpdateURLRequest
+	(*UpdateURLResponse)(nil),       // 19: proto.v1.UpdateURLResponse
+	(*DeleteURLRequest)(nil),        // 20: proto.v1.DeleteURLRequest
+	(*DeleteURLResponse)(nil),       // 21: proto.v1.DeleteURLResponse
+	(*DisableURLRequest)(nil),       // 22: proto.v1.DisableURLRequest
+	(*DisableURLResponse)(nil),      // 23: proto.v1.DisableURLResponse
+	(*APIKey)(nil),                  // 24: proto.v1.APIKey
+	(*CreateAPIKeyRequest)(nil),     // 25: proto.v1.CreateAPIKeyRequest
+	(*CreateAPIKeyResponse)(nil),    // 26: proto.v1.CreateAPIKeyResponse
+	(*ListAPIKeysRequest)(nil),      // 27: proto.v1.ListAPIKeysRequest
+	(*ListAPIKeysResponse)(nil),     // 28: proto.v1.ListAPIKeysResponse
+	(*RevokeAPIKeyRequest)(nil),     // 29: proto.v1.RevokeAPIKeyRequest
+	(*RevokeAPIKeyResponse)(nil),    // 30: proto.v1.RevokeAPIKeyResponse
+	(*timestamppb.Timestamp)(nil),   // 31: google.protobuf.Timestamp
 }
 var file_proto_v1_urlshortener_proto_depIdxs = []int32{
-	0, // 0: proto.v1.URLShortenerService.ShortenURL:input_type -> proto.v1.ShortenURLRequest
-	2, // 1: proto.v1.URLShortenerService.GetOriginalURL:input_type -> proto.v1.GetOriginalURLRequest
-	1, // 2: proto.v1.URLShortenerService.ShortenURL:output_type -> proto.v1.ShortenURLResponse
-	3, // 3: proto.v1.URLShortenerService.GetOriginalURL:output_type -> proto.v1.GetOriginalURLResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	31, // 0: proto.v1.ShortenURLRequest.expires_at:type_name -> google.protobuf.Timestamp
+	0,  // 1: proto.v1.ShortenURLRequest.query_passthrough:type_name -> proto.v1.QueryPassthrough
+	3,  // 2: proto.v1.ShortenURLRequest.targeting:type_name -> proto.v1.TargetingRule
+	4,  // 3: proto.v1.ShortenURLRequest.variants:type_name -> proto.v1.Variant
+	31, // 4: proto.v1.ShortenURLRequest.not_before:type_name -> google.protobuf.Timestamp
+	8,  // 5: proto.v1.BatchShortenURLResponse.results:type_name -> proto.v1.BatchShortenURLResult
+	31, // 6: proto.v1.GetOriginalURLResponse.expires_at:type_name -> google.protobuf.Timestamp
+	31, // 7: proto.v1.ListURLsRequest.created_after:type_name -> google.protobuf.Timestamp
+	31, // 8: proto.v1.ListURLsRequest.created_before:type_name -> google.protobuf.Timestamp
+	13, // 9: proto.v1.ListURLsResponse.urls:type_name -> proto.v1.URL
+	31, // 10: proto.v1.URL.created_at:type_name -> google.protobuf.Timestamp
+	31, // 11: proto.v1.URL.expires_at:type_name -> google.protobuf.Timestamp
+	31, // 12: proto.v1.URL.disabled_at:type_name -> google.protobuf.Timestamp
+	0,  // 13: proto.v1.URL.query_passthrough:type_name -> proto.v1.QueryPassthrough
+	3,  // 14: proto.v1.URL.targeting:type_name -> proto.v1.TargetingRule
+	4,  // 15: proto.v1.URL.variants:type_name -> proto.v1.Variant
+	31, // 16: proto.v1.URL.not_before:type_name -> google.protobuf.Timestamp
+	16, // 17: proto.v1.GetLinkStatsResponse.daily_clicks:type_name -> proto.v1.DailyClicks
+	17, // 18: proto.v1.GetLinkStatsResponse.variant_clicks:type_name -> proto.v1.VariantClicks
+	1,  // 19: proto.v1.APIKey.scope:type_name -> proto.v1.APIKeyScope
+	31, // 20: proto.v1.APIKey.created_at:type_name -> google.protobuf.Timestamp
+	31, // 21: proto.v1.APIKey.revoked_at:type_name -> google.protobuf.Timestamp
+	1,  // 22: proto.v1.CreateAPIKeyRequest.scope:type_name -> proto.v1.APIKeyScope
+	24, // 23: proto.v1.CreateAPIKeyResponse.api_key:type_name -> proto.v1.APIKey
+	24, // 24: proto.v1.ListAPIKeysResponse.api_keys:type_name -> proto.v1.APIKey
+	2,  // 25: proto.v1.URLShortenerService.ShortenURL:input_type -> proto.v1.ShortenURLRequest
+	6,  // 26: proto.v1.URLShortenerService.BatchShortenURL:input_type -> proto.v1.BatchShortenURLRequest
+	9,  // 27: proto.v1.URLShortenerService.GetOriginalURL:input_type -> proto.v1.GetOriginalURLRequest
+	11, // 28: proto.v1.URLShortenerService.ListURLs:input_type -> proto.v1.ListURLsRequest
+	14, // 29: proto.v1.URLShortenerService.GetLinkStats:input_type -> proto.v1.GetLinkStatsRequest
+	18, // 30: proto.v1.URLShortenerService.UpdateURL:input_type -> proto.v1.UpdateURLRequest
+	20, // 31: proto.v1.URLShortenerService.DeleteURL:input_type -> proto.v1.DeleteURLRequest
+	22, // 32: proto.v1.URLShortenerService.DisableURL:input_type -> proto.v1.DisableURLRequest
+	25, // 33: proto.v1.URLShortenerService.CreateAPIKey:input_type -> proto.v1.CreateAPIKeyRequest
+	27, // 34: proto.v1.URLShortenerService.ListAPIKeys:input_type -> proto.v1.ListAPIKeysRequest
+	29, // 35: proto.v1.URLShortenerService.RevokeAPIKey:input_type -> proto.v1.RevokeAPIKeyRequest
+	5,  // 36: proto.v1.URLShortenerService.ShortenURL:output_type -> proto.v1.ShortenURLResponse
+	7,  // 37: proto.v1.URLShortenerService.BatchShortenURL:output_type -> proto.v1.BatchShortenURLResponse
+	10, // 38: proto.v1.URLShortenerService.GetOriginalURL:output_type -> proto.v1.GetOriginalURLResponse
+	12, // 39: proto.v1.URLShortenerService.ListURLs:output_type -> proto.v1.ListURLsResponse
+	15, // 40: proto.v1.URLShortenerService.GetLinkStats:output_type -> proto.v1.GetLinkStatsResponse
+	19, // 41: proto.v1.URLShortenerService.UpdateURL:output_type -> proto.v1.UpdateURLResponse
+	21, // 42: proto.v1.URLShortenerService.DeleteURL:output_type -> proto.v1.DeleteURLResponse
+	23, // 43: proto.v1.URLShortenerService.DisableURL:output_type -> proto.v1.DisableURLResponse
+	26, // 44: proto.v1.URLShortenerService.CreateAPIKey:output_type -> proto.v1.CreateAPIKeyResponse
+	28, // 45: proto.v1.URLShortenerService.ListAPIKeys:output_type -> proto.v1.ListAPIKeysResponse
+	30, // 46: proto.v1.URLShortenerService.RevokeAPIKey:output_type -> proto.v1.RevokeAPIKeyResponse
+	36, // [36:47] is the sub-list for method output_type
+	25, // [25:36] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
 }
 
 func init() { file_proto_v1_urlshortener_proto_init() }
@@ -260,18 +2213,21 @@ func file_proto_v1_urlshortener_proto_init() {
 	if File_proto_v1_urlshortener_proto != nil {
 		return
 	}
+	file_proto_v1_urlshortener_proto_msgTypes[0].OneofWrappers = []any{}
+	file_proto_v1_urlshortener_proto_msgTypes[11].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_v1_urlshortener_proto_rawDesc), len(file_proto_v1_urlshortener_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      2,
+			NumMessages:   29,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_v1_urlshortener_proto_goTypes,
 		DependencyIndexes: file_proto_v1_urlshortener_proto_depIdxs,
+		EnumInfos:         file_proto_v1_urlshortener_proto_enumTypes,
 		MessageInfos:      file_proto_v1_urlshortener_proto_msgTypes,
 	}.Build()
 	File_proto_v1_urlshortener_proto = out.File